@@ -0,0 +1,43 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"github.com/expanse-org/go-expanse/common"
+	"github.com/expanse-org/go-expanse/ethdb"
+)
+
+// GetProof returns the Merkle proof for the given account, i.e. every node on
+// the path from the state root to the account leaf, RLP-encoded and keyed by
+// its keccak256 hash in the returned proofDb.
+func (s *StateDB) GetProof(addr common.Address) (*ethdb.MemDatabase, error) {
+	proofDb := ethdb.NewMemDatabase()
+	err := s.trie.Prove(addr[:], 0, proofDb)
+	return proofDb, err
+}
+
+// GetStorageProof returns the Merkle proof for the storage slot key inside
+// addr's storage trie, at whatever root that trie has in the current state.
+func (s *StateDB) GetStorageProof(addr common.Address, key common.Hash) (*ethdb.MemDatabase, error) {
+	proofDb := ethdb.NewMemDatabase()
+	trie := s.StorageTrie(addr)
+	if trie == nil {
+		return proofDb, nil
+	}
+	err := trie.Prove(key[:], 0, proofDb)
+	return proofDb, err
+}
@@ -0,0 +1,95 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/expanse-org/go-expanse/common"
+	"github.com/expanse-org/go-expanse/crypto"
+	"github.com/expanse-org/go-expanse/rlp"
+)
+
+// EIP2930Signer implements Signer for access-list transactions. Unlike
+// EIP155Signer it does not fold the chain ID into v; the chain ID is carried
+// explicitly on the transaction and the recovery id (0 or 1) is signed for
+// directly, so it also accepts legacy (non-access-list) transactions signed
+// under EIP155 rules for the same chain.
+type EIP2930Signer struct {
+	chainId, chainIdMul *big.Int
+}
+
+// NewEIP2930Signer returns a signer that accepts both access-list
+// transactions and legacy EIP155 transactions for chainId.
+func NewEIP2930Signer(chainId *big.Int) EIP2930Signer {
+	if chainId == nil {
+		chainId = new(big.Int)
+	}
+	return EIP2930Signer{chainId: chainId, chainIdMul: new(big.Int).Mul(chainId, big.NewInt(2))}
+}
+
+func (s EIP2930Signer) Equal(s2 Signer) bool {
+	other, ok := s2.(EIP2930Signer)
+	return ok && other.chainId.Cmp(s.chainId) == 0
+}
+
+// Sender recovers the sending address from tx's signature.
+func (s EIP2930Signer) Sender(tx *Transaction) (common.Address, error) {
+	if tx.Type() != AccessListTxType {
+		return EIP155Signer{chainId: s.chainId, chainIdMul: s.chainIdMul}.Sender(tx)
+	}
+	V, R, S := tx.RawSignatureValues()
+	V = new(big.Int).Add(V, big.NewInt(27))
+	return recoverPlain(s.Hash(tx), R, S, V, true)
+}
+
+// SignatureValues returns the raw R, S, V values, taking sig as produced by
+// crypto.Sign and mapping it onto the single-byte recovery id this
+// transaction type signs (no chain-id folding, unlike EIP155).
+func (s EIP2930Signer) SignatureValues(tx *Transaction, sig []byte) (R, S, V *big.Int, err error) {
+	if tx.Type() != AccessListTxType {
+		return nil, nil, nil, fmt.Errorf("EIP2930Signer.SignatureValues: expected access-list transaction, got %d", tx.Type())
+	}
+	R = new(big.Int).SetBytes(sig[:32])
+	S = new(big.Int).SetBytes(sig[32:64])
+	V = big.NewInt(int64(sig[64]))
+	return R, S, V, nil
+}
+
+// Hash returns the hash to be signed by the sender, which excludes the
+// signature fields and is prefixed by AccessListTxType to domain-separate it
+// from legacy transaction hashes.
+func (s EIP2930Signer) Hash(tx *Transaction) common.Hash {
+	atx := tx.inner.(*AccessListTx)
+	return rlpHash([]interface{}{
+		byte(AccessListTxType),
+		s.chainId,
+		atx.Nonce,
+		atx.GasPrice,
+		atx.Gas,
+		atx.To,
+		atx.Value,
+		atx.Data,
+		atx.AccessList,
+	})
+}
+
+func rlpHash(x interface{}) common.Hash {
+	enc, _ := rlp.EncodeToBytes(x)
+	return crypto.Keccak256Hash(enc)
+}
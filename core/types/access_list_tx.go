@@ -0,0 +1,173 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"math/big"
+
+	"github.com/expanse-org/go-expanse/common"
+)
+
+// AccessListTxType is the envelope type byte that prefixes the RLP payload of
+// an access-list transaction, distinguishing it from a legacy transaction
+// (whose RLP always starts with a list header >= 0xc0).
+const AccessListTxType = 0x01
+
+// AccessTuple is a single entry of an AccessList: an address together with
+// the storage slots inside it the transaction declares it will touch.
+type AccessTuple struct {
+	Address     common.Address `json:"address"`
+	StorageKeys []common.Hash  `json:"storageKeys"`
+}
+
+// AccessList is an EIP-2930 access list: a set of addresses and storage slots
+// that a transaction declares it will access. Declaring them costs a flat
+// amount per entry (see vm.AccessListGas); the gas the EVM charges per opcode
+// does not depend on whether the accessed address or slot is on the list.
+type AccessList []AccessTuple
+
+// StorageKeys returns the total number of storage keys across the access
+// list, used to compute the list's gas cost.
+func (al AccessList) StorageKeys() int {
+	sum := 0
+	for _, tuple := range al {
+		sum += len(tuple.StorageKeys)
+	}
+	return sum
+}
+
+// Equal reports whether al and other declare the same addresses and, for
+// each address, the same set of storage keys (order-independent) — used by
+// eth_createAccessList to detect it has reached a fixed point.
+func (al AccessList) Equal(other AccessList) bool {
+	if len(al) != len(other) {
+		return false
+	}
+	idx := make(map[common.Address]map[common.Hash]struct{}, len(al))
+	for _, t := range al {
+		keys := make(map[common.Hash]struct{}, len(t.StorageKeys))
+		for _, k := range t.StorageKeys {
+			keys[k] = struct{}{}
+		}
+		idx[t.Address] = keys
+	}
+	for _, t := range other {
+		keys, ok := idx[t.Address]
+		if !ok || len(keys) != len(t.StorageKeys) {
+			return false
+		}
+		for _, k := range t.StorageKeys {
+			if _, ok := keys[k]; !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// AccessListTx is the data of an EIP-2930 access-list transaction. It carries
+// everything a legacy transaction does, plus an explicit AccessList and a
+// ChainId to bind the signature to a single chain.
+type AccessListTx struct {
+	ChainId    *big.Int
+	Nonce      uint64
+	GasPrice   *big.Int
+	Gas        uint64
+	To         *common.Address `rlp:"nil"` // nil means contract creation
+	Value      *big.Int
+	Data       []byte
+	AccessList AccessList
+
+	// Signature values
+	V *big.Int
+	R *big.Int
+	S *big.Int
+}
+
+func (tx *AccessListTx) txType() byte { return AccessListTxType }
+
+func (tx *AccessListTx) copy() TxData {
+	cpy := &AccessListTx{
+		ChainId:    new(big.Int),
+		Nonce:      tx.Nonce,
+		To:         copyAddr(tx.To),
+		Data:       common.CopyBytes(tx.Data),
+		Gas:        tx.Gas,
+		AccessList: append(AccessList(nil), tx.AccessList...),
+		Value:      new(big.Int),
+		GasPrice:   new(big.Int),
+		V:          new(big.Int),
+		R:          new(big.Int),
+		S:          new(big.Int),
+	}
+	if tx.ChainId != nil {
+		cpy.ChainId.Set(tx.ChainId)
+	}
+	if tx.Value != nil {
+		cpy.Value.Set(tx.Value)
+	}
+	if tx.GasPrice != nil {
+		cpy.GasPrice.Set(tx.GasPrice)
+	}
+	if tx.V != nil {
+		cpy.V.Set(tx.V)
+	}
+	if tx.R != nil {
+		cpy.R.Set(tx.R)
+	}
+	if tx.S != nil {
+		cpy.S.Set(tx.S)
+	}
+	return cpy
+}
+
+func (tx *AccessListTx) chainId() *big.Int      { return tx.ChainId }
+func (tx *AccessListTx) accessList() AccessList { return tx.AccessList }
+func (tx *AccessListTx) data() []byte           { return tx.Data }
+func (tx *AccessListTx) gas() uint64            { return tx.Gas }
+func (tx *AccessListTx) gasPrice() *big.Int     { return tx.GasPrice }
+func (tx *AccessListTx) value() *big.Int        { return tx.Value }
+func (tx *AccessListTx) nonce() uint64          { return tx.Nonce }
+func (tx *AccessListTx) to() *common.Address    { return tx.To }
+
+func (tx *AccessListTx) rawSignatureValues() (v, r, s *big.Int) {
+	return tx.V, tx.R, tx.S
+}
+
+func (tx *AccessListTx) setSignatureValues(v, r, s *big.Int) {
+	tx.V, tx.R, tx.S = v, r, s
+}
+
+// NewAccessListTransaction creates an unsigned access-list transaction
+// wrapped in the same *Transaction envelope legacy transactions use, so the
+// rest of the stack (tx pool, RLP encoding, signer) can remain agnostic to
+// the concrete transaction type.
+func NewAccessListTransaction(chainId *big.Int, nonce uint64, to *common.Address, amount *big.Int, gasLimit uint64, gasPrice *big.Int, data []byte, accessList AccessList) *Transaction {
+	return newTransaction(&AccessListTx{
+		ChainId:    chainId,
+		Nonce:      nonce,
+		GasPrice:   gasPrice,
+		Gas:        gasLimit,
+		To:         to,
+		Value:      amount,
+		Data:       data,
+		AccessList: accessList,
+		V:          new(big.Int),
+		R:          new(big.Int),
+		S:          new(big.Int),
+	})
+}
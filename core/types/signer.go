@@ -0,0 +1,149 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/expanse-org/go-expanse/common"
+	"github.com/expanse-org/go-expanse/crypto"
+	"github.com/expanse-org/go-expanse/params"
+)
+
+// ErrInvalidSig is returned when a transaction's V, R, S signature values are
+// out of the range a Signer accepts.
+var ErrInvalidSig = errors.New("invalid transaction v, r, s values")
+
+// Signer encapsulates transaction signature handling. The name is slightly
+// misleading: a Signer doesn't sign, it only recovers/validates signatures
+// already present on a transaction and computes the hash that was signed.
+type Signer interface {
+	// Sender returns the sender address of the transaction.
+	Sender(tx *Transaction) (common.Address, error)
+	// SignatureValues returns the raw R, S, V values corresponding to the
+	// given signature, as produced by crypto.Sign for this transaction.
+	SignatureValues(tx *Transaction, sig []byte) (r, s, v *big.Int, err error)
+	// Hash returns the hash to be signed.
+	Hash(tx *Transaction) common.Hash
+	// Equal reports whether the given Signer is the same as the receiver.
+	Equal(Signer) bool
+}
+
+// Sender returns the sender address of tx, as recovered by signer.
+func Sender(signer Signer, tx *Transaction) (common.Address, error) {
+	return signer.Sender(tx)
+}
+
+// MakeSigner returns the Signer legacy transactions in config's chain should
+// be validated against at blockNumber.
+func MakeSigner(config *params.ChainConfig, blockNumber *big.Int) Signer {
+	return NewEIP155Signer(config.ChainId)
+}
+
+// EIP155Signer implements Signer using the EIP-155 rules, folding the chain
+// ID into v so a signature cannot be replayed on a different chain.
+type EIP155Signer struct {
+	chainId, chainIdMul *big.Int
+}
+
+// NewEIP155Signer returns a signer that accepts legacy transactions signed
+// for chainId under EIP-155 rules.
+func NewEIP155Signer(chainId *big.Int) EIP155Signer {
+	if chainId == nil {
+		chainId = new(big.Int)
+	}
+	return EIP155Signer{chainId: chainId, chainIdMul: new(big.Int).Mul(chainId, big.NewInt(2))}
+}
+
+func (s EIP155Signer) Equal(s2 Signer) bool {
+	other, ok := s2.(EIP155Signer)
+	return ok && other.chainId.Cmp(s.chainId) == 0
+}
+
+var big8 = big.NewInt(8)
+
+// Sender recovers the sending address from tx's signature.
+func (s EIP155Signer) Sender(tx *Transaction) (common.Address, error) {
+	if tx.Type() != LegacyTxType {
+		return common.Address{}, ErrTxTypeNotSupported
+	}
+	if chainId := tx.ChainId(); chainId != nil && chainId.Sign() != 0 && chainId.Cmp(s.chainId) != 0 {
+		return common.Address{}, fmt.Errorf("transaction chain ID %d does not match signer chain ID %d", chainId, s.chainId)
+	}
+	V, R, S := tx.RawSignatureValues()
+	V = new(big.Int).Sub(V, s.chainIdMul)
+	V.Sub(V, big8)
+	return recoverPlain(s.Hash(tx), R, S, V, true)
+}
+
+// SignatureValues returns the raw R, S, V values, taking sig as produced by
+// crypto.Sign and folding the chain ID into V per EIP-155.
+func (s EIP155Signer) SignatureValues(tx *Transaction, sig []byte) (R, S, V *big.Int, err error) {
+	R = new(big.Int).SetBytes(sig[:32])
+	S = new(big.Int).SetBytes(sig[32:64])
+	if s.chainId.Sign() != 0 {
+		V = big.NewInt(int64(sig[64] + 35))
+		V.Add(V, s.chainIdMul)
+	} else {
+		V = new(big.Int).SetBytes([]byte{sig[64] + 27})
+	}
+	return R, S, V, nil
+}
+
+// Hash returns the hash to be signed by the sender, which excludes the
+// signature fields and folds in the chain ID (with r, s placeholders of 0) so
+// the signature cannot be replayed on another chain.
+func (s EIP155Signer) Hash(tx *Transaction) common.Hash {
+	ltx := tx.inner.(*LegacyTx)
+	return rlpHash([]interface{}{
+		ltx.Nonce,
+		ltx.GasPrice,
+		ltx.Gas,
+		ltx.To,
+		ltx.Value,
+		ltx.Data,
+		s.chainId, uint(0), uint(0),
+	})
+}
+
+// recoverPlain recovers the address that produced (R, S, Vb) over sighash.
+func recoverPlain(sighash common.Hash, R, S, Vb *big.Int, homestead bool) (common.Address, error) {
+	if Vb.BitLen() > 8 {
+		return common.Address{}, ErrInvalidSig
+	}
+	V := byte(Vb.Uint64() - 27)
+	if !crypto.ValidateSignatureValues(V, R, S, homestead) {
+		return common.Address{}, ErrInvalidSig
+	}
+	r, s := R.Bytes(), S.Bytes()
+	sig := make([]byte, 65)
+	copy(sig[32-len(r):32], r)
+	copy(sig[64-len(s):64], s)
+	sig[64] = V
+	pub, err := crypto.Ecrecover(sighash[:], sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(pub) == 0 || pub[0] != 4 {
+		return common.Address{}, errors.New("invalid public key")
+	}
+	var addr common.Address
+	copy(addr[:], crypto.Keccak256(pub[1:])[12:])
+	return addr, nil
+}
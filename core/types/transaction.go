@@ -0,0 +1,316 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/expanse-org/go-expanse/common"
+	"github.com/expanse-org/go-expanse/crypto"
+	"github.com/expanse-org/go-expanse/rlp"
+)
+
+// LegacyTxType is the implicit envelope type of a pre-EIP-2718 transaction:
+// it carries no type byte of its own, distinguished from typed transactions
+// (such as AccessListTxType) by its RLP encoding starting with a list header
+// (>= 0xc0) rather than a string.
+const LegacyTxType = 0x00
+
+// ErrTxTypeNotSupported is returned when an operation is attempted on a
+// transaction type a given Signer or envelope type byte does not support.
+var ErrTxTypeNotSupported = errors.New("transaction type not supported")
+
+var errShortTypedTx = errors.New("typed transaction too short")
+
+// TxData is the type-specific payload of a Transaction, implemented by
+// LegacyTx and AccessListTx. Wrapping it behind Transaction lets the rest of
+// the stack (pool, RLP encoding, signer) stay agnostic to the concrete type.
+type TxData interface {
+	txType() byte
+	copy() TxData
+
+	chainId() *big.Int
+	accessList() AccessList
+	data() []byte
+	gas() uint64
+	gasPrice() *big.Int
+	value() *big.Int
+	nonce() uint64
+	to() *common.Address
+
+	rawSignatureValues() (v, r, s *big.Int)
+	setSignatureValues(v, r, s *big.Int)
+}
+
+// Transaction is an Expanse transaction. Its concrete type is either a
+// LegacyTx or, since EIP-2718/2930, a typed transaction such as AccessListTx;
+// TxData.txType distinguishes them and selects their wire envelope.
+type Transaction struct {
+	inner TxData
+}
+
+// newTransaction creates a Transaction wrapping the given type-specific data.
+func newTransaction(inner TxData) *Transaction {
+	return &Transaction{inner: inner}
+}
+
+// NewTransaction creates an unsigned legacy transaction.
+func NewTransaction(nonce uint64, to common.Address, amount *big.Int, gasLimit uint64, gasPrice *big.Int, data []byte) *Transaction {
+	return newTransaction(&LegacyTx{
+		Nonce:    nonce,
+		To:       &to,
+		Value:    amount,
+		Gas:      gasLimit,
+		GasPrice: gasPrice,
+		Data:     data,
+		V:        new(big.Int),
+		R:        new(big.Int),
+		S:        new(big.Int),
+	})
+}
+
+// NewContractCreation creates an unsigned legacy contract-creation transaction.
+func NewContractCreation(nonce uint64, amount *big.Int, gasLimit uint64, gasPrice *big.Int, data []byte) *Transaction {
+	return newTransaction(&LegacyTx{
+		Nonce:    nonce,
+		Value:    amount,
+		Gas:      gasLimit,
+		GasPrice: gasPrice,
+		Data:     data,
+		V:        new(big.Int),
+		R:        new(big.Int),
+		S:        new(big.Int),
+	})
+}
+
+// LegacyTx is the data of a pre-EIP-2718 transaction.
+type LegacyTx struct {
+	Nonce    uint64
+	GasPrice *big.Int
+	Gas      uint64
+	To       *common.Address `rlp:"nil"` // nil means contract creation
+	Value    *big.Int
+	Data     []byte
+
+	// Signature values
+	V *big.Int
+	R *big.Int
+	S *big.Int
+}
+
+func (tx *LegacyTx) txType() byte { return LegacyTxType }
+
+func (tx *LegacyTx) copy() TxData {
+	cpy := &LegacyTx{
+		Nonce:    tx.Nonce,
+		To:       copyAddr(tx.To),
+		Data:     common.CopyBytes(tx.Data),
+		Gas:      tx.Gas,
+		Value:    new(big.Int),
+		GasPrice: new(big.Int),
+		V:        new(big.Int),
+		R:        new(big.Int),
+		S:        new(big.Int),
+	}
+	if tx.Value != nil {
+		cpy.Value.Set(tx.Value)
+	}
+	if tx.GasPrice != nil {
+		cpy.GasPrice.Set(tx.GasPrice)
+	}
+	if tx.V != nil {
+		cpy.V.Set(tx.V)
+	}
+	if tx.R != nil {
+		cpy.R.Set(tx.R)
+	}
+	if tx.S != nil {
+		cpy.S.Set(tx.S)
+	}
+	return cpy
+}
+
+func (tx *LegacyTx) chainId() *big.Int      { return deriveChainId(tx.V) }
+func (tx *LegacyTx) accessList() AccessList { return nil }
+func (tx *LegacyTx) data() []byte           { return tx.Data }
+func (tx *LegacyTx) gas() uint64            { return tx.Gas }
+func (tx *LegacyTx) gasPrice() *big.Int     { return tx.GasPrice }
+func (tx *LegacyTx) value() *big.Int        { return tx.Value }
+func (tx *LegacyTx) nonce() uint64          { return tx.Nonce }
+func (tx *LegacyTx) to() *common.Address    { return tx.To }
+
+func (tx *LegacyTx) rawSignatureValues() (v, r, s *big.Int) {
+	return tx.V, tx.R, tx.S
+}
+
+func (tx *LegacyTx) setSignatureValues(v, r, s *big.Int) {
+	tx.V, tx.R, tx.S = v, r, s
+}
+
+// Type returns the EIP-2718 envelope type: LegacyTxType for a pre-2718
+// transaction, or the type byte the transaction was constructed/decoded with.
+func (tx *Transaction) Type() byte { return tx.inner.txType() }
+
+func (tx *Transaction) ChainId() *big.Int      { return tx.inner.chainId() }
+func (tx *Transaction) AccessList() AccessList { return tx.inner.accessList() }
+func (tx *Transaction) Data() []byte           { return tx.inner.data() }
+func (tx *Transaction) Gas() uint64            { return tx.inner.gas() }
+func (tx *Transaction) GasPrice() *big.Int     { return tx.inner.gasPrice() }
+func (tx *Transaction) Value() *big.Int        { return tx.inner.value() }
+func (tx *Transaction) Nonce() uint64          { return tx.inner.nonce() }
+func (tx *Transaction) To() *common.Address    { return copyAddr(tx.inner.to()) }
+
+// RawSignatureValues returns the raw V, R, S signature values carried in the
+// transaction's envelope, as set by Signer.SignatureValues.
+func (tx *Transaction) RawSignatureValues() (v, r, s *big.Int) {
+	return tx.inner.rawSignatureValues()
+}
+
+// Hash returns the transaction hash, prefixed by its envelope type for
+// anything but a legacy transaction so the two families can never collide.
+func (tx *Transaction) Hash() common.Hash {
+	if tx.Type() == LegacyTxType {
+		return rlpHash(tx.inner)
+	}
+	return prefixedRlpHash(tx.Type(), tx.inner)
+}
+
+// EncodeRLP implements rlp.Encoder: a legacy transaction encodes as its bare
+// RLP list (unchanged wire format); a typed transaction encodes, per
+// EIP-2718, as an RLP string containing TransactionType || TransactionPayload.
+func (tx *Transaction) EncodeRLP(w io.Writer) error {
+	if tx.Type() == LegacyTxType {
+		return rlp.Encode(w, tx.inner)
+	}
+	payload, err := rlp.EncodeToBytes(tx.inner)
+	if err != nil {
+		return err
+	}
+	return rlp.Encode(w, append([]byte{tx.Type()}, payload...))
+}
+
+// DecodeRLP implements rlp.Decoder. It dispatches on the envelope: a payload
+// beginning with a list header decodes as a legacy transaction; otherwise its
+// first byte is the EIP-2718 type selecting which typed transaction the rest
+// decodes into.
+func (tx *Transaction) DecodeRLP(s *rlp.Stream) error {
+	kind, _, err := s.Kind()
+	if err != nil {
+		return err
+	}
+	if kind == rlp.List {
+		var inner LegacyTx
+		if err := s.Decode(&inner); err != nil {
+			return err
+		}
+		tx.inner = &inner
+		return nil
+	}
+	b, err := s.Bytes()
+	if err != nil {
+		return err
+	}
+	if len(b) == 0 {
+		return errShortTypedTx
+	}
+	var inner TxData
+	switch b[0] {
+	case AccessListTxType:
+		inner = new(AccessListTx)
+	default:
+		return ErrTxTypeNotSupported
+	}
+	if err := rlp.DecodeBytes(b[1:], inner); err != nil {
+		return err
+	}
+	tx.inner = inner
+	return nil
+}
+
+// Message is the fully derived form of a Transaction, with the sender
+// recovered from its signature, and implements core.Message.
+type Message struct {
+	to         *common.Address
+	from       common.Address
+	nonce      uint64
+	amount     *big.Int
+	gasLimit   uint64
+	gasPrice   *big.Int
+	data       []byte
+	accessList AccessList
+	checkNonce bool
+}
+
+func (m Message) From() common.Address   { return m.from }
+func (m Message) To() *common.Address    { return m.to }
+func (m Message) GasPrice() *big.Int     { return m.gasPrice }
+func (m Message) Value() *big.Int        { return m.amount }
+func (m Message) Gas() uint64            { return m.gasLimit }
+func (m Message) Nonce() uint64          { return m.nonce }
+func (m Message) Data() []byte           { return m.data }
+func (m Message) AccessList() AccessList { return m.accessList }
+func (m Message) CheckNonce() bool       { return m.checkNonce }
+
+// AsMessage derives a Message from tx, recovering the sender via s.
+func (tx *Transaction) AsMessage(s Signer) (Message, error) {
+	msg := Message{
+		nonce:      tx.Nonce(),
+		gasLimit:   tx.Gas(),
+		gasPrice:   new(big.Int).Set(tx.GasPrice()),
+		to:         tx.To(),
+		amount:     tx.Value(),
+		data:       tx.Data(),
+		accessList: tx.AccessList(),
+		checkNonce: true,
+	}
+	var err error
+	msg.from, err = Sender(s, tx)
+	return msg, err
+}
+
+func copyAddr(a *common.Address) *common.Address {
+	if a == nil {
+		return nil
+	}
+	cpy := *a
+	return &cpy
+}
+
+// deriveChainId derives the chain id from an EIP-155 v value, following
+// v = {0,1} + chainId*2 + 35. A pre-EIP-155 v (27 or 28) yields a zero
+// chain id, since those transactions don't carry one.
+func deriveChainId(v *big.Int) *big.Int {
+	if v == nil {
+		return new(big.Int)
+	}
+	if v.BitLen() <= 64 {
+		vv := v.Uint64()
+		if vv == 27 || vv == 28 {
+			return new(big.Int)
+		}
+		return new(big.Int).SetUint64((vv - 35) / 2)
+	}
+	vCopy := new(big.Int).Sub(v, big.NewInt(35))
+	return vCopy.Div(vCopy, big.NewInt(2))
+}
+
+func prefixedRlpHash(prefix byte, x interface{}) common.Hash {
+	enc, _ := rlp.EncodeToBytes(x)
+	return crypto.Keccak256Hash(append([]byte{prefix}, enc...))
+}
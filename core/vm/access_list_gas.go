@@ -0,0 +1,44 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import "github.com/expanse-org/go-expanse/core/types"
+
+// EIP-2930 access-list pricing: declaring an address or storage slot up front
+// costs a flat amount of gas per entry, as computed by AccessListGas.
+const (
+	TxAccessListAddressGas  uint64 = 2400
+	TxAccessListStorageKeyGas uint64 = 1900
+)
+
+// TODO(EIP-2929/2930 follow-up): this only charges the flat up-front
+// surcharge for declaring an access list entry. The reduced per-opcode
+// cold/warm gas cost for SLOAD/BALANCE/EXTCODE*/CALL against a declared
+// address or slot is not implemented — that needs access-list-aware state
+// tracking in core/state and matching changes to the interpreter's opcode
+// gas calculation, neither of which this package has.
+
+// AccessListGas returns the portion of a transaction's intrinsic gas
+// contributed by its access list, charged once up front in addition to the
+// base tx gas, regardless of how many times execution actually touches each
+// declared address/slot.
+func AccessListGas(list types.AccessList) uint64 {
+	var gas uint64
+	gas += uint64(len(list)) * TxAccessListAddressGas
+	gas += uint64(list.StorageKeys()) * TxAccessListStorageKeyGas
+	return gas
+}
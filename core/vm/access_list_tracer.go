@@ -0,0 +1,127 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+
+	"github.com/expanse-org/go-expanse/common"
+	"github.com/expanse-org/go-expanse/core/types"
+)
+
+// AccessListTracer is a Tracer that records every address and storage slot
+// touched by SLOAD, BALANCE, EXTCODESIZE/EXTCODEHASH/EXTCODECOPY and the
+// CALL family of opcodes during a single message call, so the access list it
+// produces can be handed back to the caller as a gas-optimal eth_createAccessList
+// suggestion.
+type AccessListTracer struct {
+	excl  map[common.Address]struct{} // pre-existing addresses that don't need listing (sender, precompiles, to)
+	list  map[common.Address]map[common.Hash]struct{}
+	order []common.Address
+}
+
+// NewAccessListTracer creates a tracer seeded with the entries of acl. The
+// message's own sender and destination (from, to) and the given precompiles
+// are always accessed for free and so never appear in the suggested list.
+func NewAccessListTracer(acl types.AccessList, from, to common.Address, precompiles []common.Address) *AccessListTracer {
+	excl := map[common.Address]struct{}{from: {}, to: {}}
+	for _, addr := range precompiles {
+		excl[addr] = struct{}{}
+	}
+	t := &AccessListTracer{
+		excl: excl,
+		list: make(map[common.Address]map[common.Hash]struct{}),
+	}
+	for _, tuple := range acl {
+		t.addAddress(tuple.Address)
+		for _, key := range tuple.StorageKeys {
+			t.addSlot(tuple.Address, key)
+		}
+	}
+	return t
+}
+
+func (t *AccessListTracer) addAddress(addr common.Address) {
+	if _, ok := t.excl[addr]; ok {
+		return
+	}
+	if _, ok := t.list[addr]; !ok {
+		t.list[addr] = make(map[common.Hash]struct{})
+		t.order = append(t.order, addr)
+	}
+}
+
+func (t *AccessListTracer) addSlot(addr common.Address, slot common.Hash) {
+	t.addAddress(addr)
+	if _, ok := t.excl[addr]; ok {
+		return
+	}
+	t.list[addr][slot] = struct{}{}
+}
+
+// CaptureStart implements Tracer; it is a no-op, the interesting work happens
+// in CaptureState.
+func (t *AccessListTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	return nil
+}
+
+// CaptureState implements Tracer, inspecting the opcode about to execute and
+// the current stack to learn which address/slot it is about to touch.
+func (t *AccessListTracer) CaptureState(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error {
+	stackData := stack.Data()
+	stackLen := len(stackData)
+	switch {
+	case op == SLOAD && stackLen >= 1:
+		slot := common.BigToHash(stackData[stackLen-1])
+		t.addSlot(contract.Address(), slot)
+	case op == EXTCODECOPY || op == EXTCODEHASH || op == EXTCODESIZE || op == BALANCE:
+		if stackLen >= 1 {
+			t.addAddress(common.BigToAddress(stackData[stackLen-1]))
+		}
+	case (op == CALL || op == CALLCODE || op == DELEGATECALL || op == STATICCALL) && stackLen >= 5:
+		t.addAddress(common.BigToAddress(stackData[stackLen-2]))
+	}
+	return nil
+}
+
+// CaptureFault implements Tracer; faults don't change what has already been
+// recorded, so this is a no-op.
+func (t *AccessListTracer) CaptureFault(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureEnd implements Tracer; it is a no-op, the interesting work happens
+// in CaptureState.
+func (t *AccessListTracer) CaptureEnd(output []byte, gasUsed uint64, tm interface{}, err error) error {
+	return nil
+}
+
+// AccessList returns the access list accumulated so far, in the order
+// addresses were first touched.
+func (t *AccessListTracer) AccessList() types.AccessList {
+	acl := make(types.AccessList, 0, len(t.order))
+	for _, addr := range t.order {
+		slots := t.list[addr]
+		tuple := types.AccessTuple{Address: addr}
+		for slot := range slots {
+			tuple.StorageKeys = append(tuple.StorageKeys, slot)
+		}
+		acl = append(acl, tuple)
+	}
+	return acl
+}
+
@@ -0,0 +1,154 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+
+	"github.com/expanse-org/go-expanse/common"
+)
+
+// Storage represents a contract's storage as seen by the logger: a snapshot
+// of slot/value pairs touched during a captured call.
+type Storage map[common.Hash]common.Hash
+
+// Copy duplicates the current storage.
+func (s Storage) Copy() Storage {
+	cpy := make(Storage, len(s))
+	for key, value := range s {
+		cpy[key] = value
+	}
+	return cpy
+}
+
+// LogConfig are the configuration options for structured logger the EVM.
+type LogConfig struct {
+	DisableMemory  bool // disable memory capture
+	DisableStack   bool // disable stack capture
+	DisableStorage bool // disable storage capture
+	Limit          int  // maximum number of result entries
+}
+
+// StructLog is emitted to the EVM each cycle and lists information about the
+// current internal state prior to the execution of the statement.
+type StructLog struct {
+	Pc         uint64                      `json:"pc"`
+	Op         OpCode                      `json:"op"`
+	Gas        uint64                      `json:"gas"`
+	GasCost    uint64                      `json:"gasCost"`
+	Memory     []byte                      `json:"memory"`
+	MemorySize int                         `json:"memSize"`
+	Stack      []*big.Int                  `json:"stack"`
+	Storage    map[common.Hash]common.Hash `json:"-"`
+	Depth      int                         `json:"depth"`
+	Err        error                       `json:"-"`
+}
+
+// StructLogger is a Tracer that collects execution traces as a flat list of
+// StructLog entries, one per executed opcode — the "struct logger" format
+// used by the default debug_traceTransaction output.
+type StructLogger struct {
+	cfg LogConfig
+
+	logs    []StructLog
+	output  []byte
+	err     error
+	gasUsed uint64
+
+	// changedValues accumulates, per contract, the storage slots CaptureState
+	// has seen written since the trace started, so each StructLog can report
+	// the full set of storage known so far rather than just the current
+	// opcode's slot.
+	changedValues map[common.Address]Storage
+}
+
+// NewStructLogger returns a new logger.
+func NewStructLogger(cfg *LogConfig) *StructLogger {
+	logger := &StructLogger{changedValues: make(map[common.Address]Storage)}
+	if cfg != nil {
+		logger.cfg = *cfg
+	}
+	return logger
+}
+
+// CaptureStart implements the Tracer interface.
+func (l *StructLogger) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	return nil
+}
+
+// CaptureState records a new structured log entry, appending it to the
+// logger's list unless Limit entries have already been recorded.
+func (l *StructLogger) CaptureState(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error {
+	if l.cfg.Limit != 0 && len(l.logs) >= l.cfg.Limit {
+		return nil
+	}
+	var mem []byte
+	if !l.cfg.DisableMemory {
+		mem = memory.Data()
+	}
+	var stck []*big.Int
+	if !l.cfg.DisableStack {
+		stck = append(stck, stack.Data()...)
+	}
+
+	var storage Storage
+	if !l.cfg.DisableStorage {
+		contractAddr := contract.Address()
+		if op == SSTORE && len(stack.Data()) >= 2 {
+			data := stack.Data()
+			key, val := common.BigToHash(data[len(data)-1]), common.BigToHash(data[len(data)-2])
+			if l.changedValues[contractAddr] == nil {
+				l.changedValues[contractAddr] = make(Storage)
+			}
+			l.changedValues[contractAddr][key] = val
+		}
+		storage = l.changedValues[contractAddr].Copy()
+	}
+
+	log := StructLog{pc, op, gas, cost, mem, memory.Len(), stck, storage, depth, err}
+	l.logs = append(l.logs, log)
+	return nil
+}
+
+// CaptureFault implements the Tracer interface to trace an execution fault.
+func (l *StructLogger) CaptureFault(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureEnd is called after the call finishes, recording its final output
+// and error (if any) so GetResult can report them alongside the step log.
+func (l *StructLogger) CaptureEnd(output []byte, gasUsed uint64, t interface{}, err error) error {
+	l.output = output
+	l.err = err
+	l.gasUsed = gasUsed
+	return nil
+}
+
+// StructLogs returns the captured log entries.
+func (l *StructLogger) StructLogs() []StructLog {
+	return l.logs
+}
+
+// Error returns the VM error captured by the trace.
+func (l *StructLogger) Error() error { return l.err }
+
+// Output returns the VM return value captured by the trace.
+func (l *StructLogger) Output() []byte { return l.output }
+
+// GasUsed returns the total gas the traced call spent, as reported by
+// CaptureEnd.
+func (l *StructLogger) GasUsed() uint64 { return l.gasUsed }
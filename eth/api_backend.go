@@ -18,10 +18,12 @@ package eth
 
 import (
 	"context"
+	"fmt"
 	"math/big"
 
 	"github.com/expanse-org/go-expanse/accounts"
 	"github.com/expanse-org/go-expanse/common"
+	"github.com/expanse-org/go-expanse/common/hexutil"
 	"github.com/expanse-org/go-expanse/common/math"
 	"github.com/expanse-org/go-expanse/core"
 	"github.com/expanse-org/go-expanse/core/state"
@@ -29,6 +31,7 @@ import (
 	"github.com/expanse-org/go-expanse/core/vm"
 	"github.com/expanse-org/go-expanse/eth/downloader"
 	"github.com/expanse-org/go-expanse/eth/gasprice"
+	"github.com/expanse-org/go-expanse/eth/tracers"
 	"github.com/expanse-org/go-expanse/ethdb"
 	"github.com/expanse-org/go-expanse/event"
 	"github.com/expanse-org/go-expanse/internal/ethapi"
@@ -118,6 +121,81 @@ func (b *EthApiBackend) GetEVM(ctx context.Context, msg core.Message, state etha
 	return vm.NewEVM(context, statedb, b.eth.chainConfig, vmCfg), vmError, nil
 }
 
+// TraceTransaction replays the block containing txHash up to and including
+// that transaction, reconstructing its exact pre-state by re-executing every
+// earlier transaction with a no-op tracer, then re-runs txHash itself with
+// the tracer selected by config.
+func (b *EthApiBackend) TraceTransaction(ctx context.Context, txHash common.Hash, config *tracers.TraceConfig) (interface{}, error) {
+	tx, blockHash, _, index := core.GetTransaction(b.eth.chainDb, txHash)
+	if tx == nil {
+		return nil, fmt.Errorf("transaction %#x not found", txHash)
+	}
+	block := b.eth.blockchain.GetBlockByHash(blockHash)
+	if block == nil {
+		return nil, fmt.Errorf("block %#x not found", blockHash)
+	}
+	parent := b.eth.blockchain.GetBlock(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, fmt.Errorf("parent of block %#x not found", blockHash)
+	}
+	statedb, err := b.eth.BlockChain().StateAt(parent.Root())
+	if err != nil {
+		return nil, err
+	}
+
+	signer := types.MakeSigner(b.eth.chainConfig, block.Number())
+	for i, txn := range block.Transactions() {
+		msg, err := txn.AsMessage(signer)
+		if err != nil {
+			return nil, err
+		}
+		context := core.NewEVMContext(msg, block.Header(), b.eth.BlockChain(), nil)
+
+		if uint64(i) == index {
+			tracer, err := tracers.New(config.TracerName())
+			if err != nil {
+				return nil, err
+			}
+			vmenv := vm.NewEVM(context, statedb, b.eth.chainConfig, vm.Config{Tracer: tracer, Debug: true})
+			if _, _, _, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.Gas())); err != nil {
+				return nil, fmt.Errorf("tracing failed: %v", err)
+			}
+			return tracer.GetResult()
+		}
+
+		vmenv := vm.NewEVM(context, statedb, b.eth.chainConfig, vm.Config{})
+		if _, _, _, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.Gas())); err != nil {
+			return nil, fmt.Errorf("transaction %#x failed: %v", txn.Hash(), err)
+		}
+		statedb.Finalise(true)
+	}
+	return nil, fmt.Errorf("transaction index %d not found in block %#x", index, blockHash)
+}
+
+// TraceCall runs msg against the state at blockNr with the tracer selected
+// by config installed, instead of applying the resulting state changes.
+func (b *EthApiBackend) TraceCall(ctx context.Context, msg core.Message, blockNr rpc.BlockNumber, config *tracers.TraceConfig) (interface{}, error) {
+	state, header, err := b.StateAndHeaderByNumber(ctx, blockNr)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	tracer, err := tracers.New(config.TracerName())
+	if err != nil {
+		return nil, err
+	}
+	evm, vmError, err := b.GetEVM(ctx, msg, state, header, vm.Config{Tracer: tracer, Debug: true})
+	if err != nil {
+		return nil, err
+	}
+	if _, _, _, err := core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(msg.Gas())); err != nil {
+		return nil, fmt.Errorf("tracing failed: %v", err)
+	}
+	if err := vmError(); err != nil {
+		return nil, err
+	}
+	return tracer.GetResult()
+}
+
 func (b *EthApiBackend) SendTx(ctx context.Context, signedTx *types.Transaction) error {
 	b.eth.txMu.Lock()
 	defer b.eth.txMu.Unlock()
@@ -197,6 +275,30 @@ func (b *EthApiBackend) EventMux() *event.TypeMux {
 	return b.eth.EventMux()
 }
 
+func (b *EthApiBackend) SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription {
+	return b.eth.BlockChain().SubscribeRemovedLogsEvent(ch)
+}
+
+func (b *EthApiBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription {
+	return b.eth.BlockChain().SubscribeChainEvent(ch)
+}
+
+func (b *EthApiBackend) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
+	return b.eth.BlockChain().SubscribeChainHeadEvent(ch)
+}
+
+func (b *EthApiBackend) SubscribeChainSideEvent(ch chan<- core.ChainSideEvent) event.Subscription {
+	return b.eth.BlockChain().SubscribeChainSideEvent(ch)
+}
+
+func (b *EthApiBackend) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription {
+	return b.eth.BlockChain().SubscribeLogsEvent(ch)
+}
+
+func (b *EthApiBackend) SubscribeTxPreEvent(ch chan<- core.TxPreEvent) event.Subscription {
+	return b.eth.TxPool().SubscribeTxPreEvent(ch)
+}
+
 func (b *EthApiBackend) AccountManager() *accounts.Manager {
 	return b.eth.AccountManager()
 }
@@ -205,6 +307,14 @@ type EthApiState struct {
 	state *state.StateDB
 }
 
+// Copy returns an independent snapshot of the state, so callers that need to
+// try several speculative executions (e.g. eth_createAccessList's
+// convergence loop) can discard one attempt's mutations without disturbing
+// the original.
+func (s EthApiState) Copy() ethapi.State {
+	return EthApiState{s.state.Copy()}
+}
+
 func (s EthApiState) GetBalance(ctx context.Context, addr common.Address) (*big.Int, error) {
 	return s.state.GetBalance(addr), nil
 }
@@ -220,3 +330,50 @@ func (s EthApiState) GetState(ctx context.Context, a common.Address, b common.Ha
 func (s EthApiState) GetNonce(ctx context.Context, addr common.Address) (uint64, error) {
 	return s.state.GetNonce(addr), nil
 }
+
+// GetProof returns the EIP-1186 account and storage proofs for addr at this
+// state, proving the account against the state root and each requested slot
+// against the account's storage root.
+func (s EthApiState) GetProof(ctx context.Context, addr common.Address, storageKeys []string) (*ethapi.AccountResult, error) {
+	accountProofDb, err := s.state.GetProof(addr)
+	if err != nil {
+		return nil, err
+	}
+	storageHash := common.Hash{}
+	if trie := s.state.StorageTrie(addr); trie != nil {
+		storageHash = trie.Hash()
+	}
+	result := &ethapi.AccountResult{
+		Address:      addr,
+		AccountProof: nodeListFromDb(accountProofDb),
+		Balance:      (*hexutil.Big)(s.state.GetBalance(addr)),
+		CodeHash:     s.state.GetCodeHash(addr),
+		Nonce:        hexutil.Uint64(s.state.GetNonce(addr)),
+		StorageHash:  storageHash,
+	}
+	for _, key := range storageKeys {
+		hash := common.HexToHash(key)
+		storageProofDb, err := s.state.GetStorageProof(addr, hash)
+		if err != nil {
+			return nil, err
+		}
+		result.StorageProof = append(result.StorageProof, ethapi.StorageResult{
+			Key:   key,
+			Value: (*hexutil.Big)(s.state.GetState(addr, hash).Big()),
+			Proof: nodeListFromDb(storageProofDb),
+		})
+	}
+	return result, nil
+}
+
+// nodeListFromDb drains a proof database produced by state.StateDB.GetProof
+// into the hex-encoded node list expected by the eth_getProof response.
+func nodeListFromDb(db *ethdb.MemDatabase) []string {
+	var nodes []string
+	for _, key := range db.Keys() {
+		if val, err := db.Get(key); err == nil {
+			nodes = append(nodes, hexutil.Encode(val))
+		}
+	}
+	return nodes
+}
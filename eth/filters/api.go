@@ -0,0 +1,176 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+
+	"github.com/expanse-org/go-expanse/common"
+	"github.com/expanse-org/go-expanse/core/types"
+	"github.com/expanse-org/go-expanse/eth/downloader"
+	"github.com/expanse-org/go-expanse/event"
+	"github.com/expanse-org/go-expanse/rpc"
+)
+
+// PublicFilterAPI offers support to create and manage filters, exposed as
+// eth_subscribe/eth_unsubscribe over a notifying (websocket/IPC) transport.
+type PublicFilterAPI struct {
+	backend   Backend
+	mux       *event.TypeMux
+	events    *EventSystem
+	lightMode bool
+}
+
+// NewPublicFilterAPI returns a new PublicFilterAPI instance, starting the
+// EventSystem that feeds it.
+func NewPublicFilterAPI(backend Backend, lightMode bool) *PublicFilterAPI {
+	return &PublicFilterAPI{
+		backend:   backend,
+		mux:       backend.EventMux(),
+		events:    NewEventSystem(backend.EventMux(), backend, lightMode),
+		lightMode: lightMode,
+	}
+}
+
+// NewHeads sends a notification each time a new (header) block is appended
+// to the chain.
+func (api *PublicFilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+	go func() {
+		headers := make(chan *types.Header)
+		headersSub := api.events.SubscribeNewHeads(headers)
+
+		for {
+			select {
+			case h := <-headers:
+				notifier.Notify(rpcSub.ID, h)
+			case <-rpcSub.Err():
+				headersSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				headersSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
+// Logs creates a subscription that fires for each new log entry matching
+// crit as blocks are appended to the chain.
+func (api *PublicFilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+	logsSub, err := api.events.SubscribeLogs(crit)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		for {
+			select {
+			case logs := <-logsSub.f.logs:
+				for _, log := range logs {
+					notifier.Notify(rpcSub.ID, log)
+				}
+			case <-rpcSub.Err():
+				logsSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				logsSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
+// NewPendingTransactions sends a notification for every new transaction that
+// enters the local transaction pool.
+func (api *PublicFilterAPI) NewPendingTransactions(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+	go func() {
+		txHashes := make(chan common.Hash)
+		txsSub := api.events.SubscribePendingTxs(txHashes)
+
+		for {
+			select {
+			case h := <-txHashes:
+				notifier.Notify(rpcSub.ID, h)
+			case <-rpcSub.Err():
+				txsSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				txsSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
+// Syncing sends a notification every time the downloader starts, finishes or
+// fails a sync, carrying the posted downloader.StartEvent, DoneEvent or
+// FailedEvent value as is.
+func (api *PublicFilterAPI) Syncing(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+	go func() {
+		sub := api.mux.Subscribe(downloader.StartEvent{}, downloader.DoneEvent{}, downloader.FailedEvent{})
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case ev, ok := <-sub.Chan():
+				if !ok {
+					return
+				}
+				notifier.Notify(rpcSub.ID, ev.Data)
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
+// GetLogs returns logs matching crit, searching already-imported blocks
+// directly (reusing the same filterLogs matcher the live subscriptions use).
+func (api *PublicFilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([]*types.Log, error) {
+	filter := NewRangeFilter(api.backend, int64(crit.FromBlock), int64(crit.ToBlock), crit.Addresses, crit.Topics)
+	return filter.Logs(ctx)
+}
@@ -0,0 +1,141 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package filters
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/expanse-org/go-expanse/common"
+	"github.com/expanse-org/go-expanse/core/types"
+	"github.com/expanse-org/go-expanse/rpc"
+)
+
+// FilterCriteria is the RPC-level description of an eth_getLogs / logs
+// subscription query: blocks in [FromBlock, ToBlock], optionally restricted
+// to Addresses and matching Topics (outer slice is positions that must all
+// match, inner slice is an OR'd set of alternatives for that position; an
+// empty inner slice matches anything).
+type FilterCriteria struct {
+	FromBlock rpc.BlockNumber
+	ToBlock   rpc.BlockNumber
+	Addresses []common.Address
+	Topics    [][]common.Hash
+}
+
+// Filter evaluates a FilterCriteria against a range of already-imported
+// blocks by fetching their receipts through the backend (eth_getLogs). The
+// logs subscription does not use Filter; it calls filterLogs directly.
+type Filter struct {
+	backend Backend
+	crit    FilterCriteria
+}
+
+// NewRangeFilter creates a Filter that matches logs in blocks [begin, end]
+// (inclusive) against addresses/topics.
+func NewRangeFilter(backend Backend, begin, end int64, addresses []common.Address, topics [][]common.Hash) *Filter {
+	return &Filter{
+		backend: backend,
+		crit: FilterCriteria{
+			FromBlock: rpc.BlockNumber(begin),
+			ToBlock:   rpc.BlockNumber(end),
+			Addresses: addresses,
+			Topics:    topics,
+		},
+	}
+}
+
+// Logs searches the block range specified by the filter and returns all
+// matching logs, fetching receipts for each candidate block via the backend.
+func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
+	var logs []*types.Log
+	from := int64(f.crit.FromBlock)
+	to := int64(f.crit.ToBlock)
+	if to == int64(rpc.LatestBlockNumber) {
+		head, err := f.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+		if err != nil {
+			return nil, err
+		}
+		to = head.Number.Int64()
+	}
+	for num := from; num <= to; num++ {
+		header, err := f.backend.HeaderByNumber(ctx, rpc.BlockNumber(num))
+		if header == nil || err != nil {
+			return nil, err
+		}
+		receipts, err := f.backend.GetReceipts(ctx, header.Hash())
+		if err != nil {
+			return nil, err
+		}
+		var blockLogs []*types.Log
+		for _, receipt := range receipts {
+			blockLogs = append(blockLogs, receipt.Logs...)
+		}
+		logs = append(logs, filterLogs(blockLogs, big.NewInt(from), big.NewInt(to), f.crit.Addresses, f.crit.Topics)...)
+	}
+	return logs, nil
+}
+
+// filterLogs applies an address/topic match against an already-fetched batch
+// of logs; it does not touch the database, so the subscription path can reuse
+// it on live logs and Filter.Logs can reuse it on historical ones.
+func filterLogs(logs []*types.Log, fromBlock, toBlock *big.Int, addresses []common.Address, topics [][]common.Hash) []*types.Log {
+	var ret []*types.Log
+Logs:
+	for _, log := range logs {
+		if fromBlock != nil && fromBlock.Int64() >= 0 && fromBlock.Uint64() > log.BlockNumber {
+			continue
+		}
+		if toBlock != nil && toBlock.Int64() >= 0 && toBlock.Uint64() < log.BlockNumber {
+			continue
+		}
+		if len(addresses) > 0 && !includesAddress(addresses, log.Address) {
+			continue
+		}
+		if len(topics) > len(log.Topics) {
+			continue
+		}
+		for i, sub := range topics {
+			if len(sub) == 0 {
+				continue // wildcard
+			}
+			if !includesTopic(sub, log.Topics[i]) {
+				continue Logs
+			}
+		}
+		ret = append(ret, log)
+	}
+	return ret
+}
+
+func includesAddress(addresses []common.Address, addr common.Address) bool {
+	for _, a := range addresses {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
+
+func includesTopic(topics []common.Hash, topic common.Hash) bool {
+	for _, t := range topics {
+		if t == topic {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,300 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package filters implements an ethereum filtering system for block,
+// transaction and log events, exposed over RPC as eth_subscribe/eth_unsubscribe
+// and eth_getLogs.
+package filters
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/expanse-org/go-expanse/common"
+	"github.com/expanse-org/go-expanse/core"
+	"github.com/expanse-org/go-expanse/core/types"
+	"github.com/expanse-org/go-expanse/ethdb"
+	"github.com/expanse-org/go-expanse/event"
+	"github.com/expanse-org/go-expanse/rpc"
+)
+
+// Backend is the subset of ethapi.Backend the filter system needs: feed
+// subscriptions to drive live events, plus enough chain/db access to answer
+// eth_getLogs for historical ranges.
+type Backend interface {
+	ChainDb() ethdb.Database
+	EventMux() *event.TypeMux
+	HeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Header, error)
+	GetReceipts(ctx context.Context, blockHash common.Hash) (types.Receipts, error)
+
+	SubscribeTxPreEvent(chan<- core.TxPreEvent) event.Subscription
+	SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription
+	SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription
+	SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription
+}
+
+// Type determines the kind of filter and is used to put the filter in to
+// the correct bucket when added.
+type Type byte
+
+const (
+	// PendingTransactionsSubscription queries for pending transactions entering the pending state
+	PendingTransactionsSubscription Type = iota
+	// BlocksSubscription queries headers for blocks that are imported
+	BlocksSubscription
+	// LogsSubscription queries logs included in new imported blocks
+	LogsSubscription
+	// PendingLogsSubscription queries logs for the pending block
+	PendingLogsSubscription
+)
+
+const (
+	// subscription channels have some slack so a slow RPC client doesn't stall the feed producer
+	chainEvChanSize = 10
+	txChanSize      = 4096
+	rmLogsChanSize  = 10
+	logsChanSize    = 10
+)
+
+var (
+	ErrInvalidSubscriptionID = errInvalid("invalid id")
+)
+
+type errInvalid string
+
+func (e errInvalid) Error() string { return string(e) }
+
+type subscription struct {
+	id        rpc.ID
+	typ       Type
+	created   time.Time
+	logsCrit  FilterCriteria
+	logs      chan []*types.Log
+	hashes    chan common.Hash
+	headers   chan *types.Header
+	installed chan struct{} // closed when the subscription is installed
+	err       chan error    // closed when the subscription is uninstalled
+}
+
+// EventSystem creates subscriptions, processes events and broadcasts them to
+// the subscribers. It fans out a single copy of each upstream Feed event to
+// every matching subscription, so the cost of N subscribers is one feed read
+// plus N cheap channel sends.
+type EventSystem struct {
+	mux       *event.TypeMux
+	backend   Backend
+	lightMode bool
+
+	install   chan *subscription
+	uninstall chan *subscription
+
+	txsCh  chan core.TxPreEvent
+	txsSub event.Subscription
+
+	logsCh  chan []*types.Log
+	logsSub event.Subscription
+
+	rmLogsCh  chan core.RemovedLogsEvent
+	rmLogsSub event.Subscription
+
+	chainCh  chan core.ChainEvent
+	chainSub event.Subscription
+}
+
+// NewEventSystem starts the event system goroutine that fans upstream feed
+// events out to every live subscription, and returns it.
+func NewEventSystem(mux *event.TypeMux, backend Backend, lightMode bool) *EventSystem {
+	m := &EventSystem{
+		mux:       mux,
+		backend:   backend,
+		lightMode: lightMode,
+		install:   make(chan *subscription),
+		uninstall: make(chan *subscription),
+		txsCh:     make(chan core.TxPreEvent, txChanSize),
+		logsCh:    make(chan []*types.Log, logsChanSize),
+		rmLogsCh:  make(chan core.RemovedLogsEvent, rmLogsChanSize),
+		chainCh:   make(chan core.ChainEvent, chainEvChanSize),
+	}
+	m.txsSub = backend.SubscribeTxPreEvent(m.txsCh)
+	m.logsSub = backend.SubscribeLogsEvent(m.logsCh)
+	m.rmLogsSub = backend.SubscribeRemovedLogsEvent(m.rmLogsCh)
+	m.chainSub = backend.SubscribeChainEvent(m.chainCh)
+
+	go m.eventLoop()
+	return m
+}
+
+// Subscription is the RPC-facing handle for a subscription created through
+// the EventSystem; Unsubscribe tears it down.
+type Subscription struct {
+	ID        rpc.ID
+	f         *subscription
+	es        *EventSystem
+	unsubOnce sync.Once
+}
+
+// Err returns a channel that is closed when the subscription has ended.
+func (sub *Subscription) Err() <-chan error {
+	return sub.f.err
+}
+
+// Unsubscribe removes the subscription from the event system and waits until
+// its Err channel is closed. The logs/hashes/headers channels stay open.
+func (sub *Subscription) Unsubscribe() {
+	sub.unsubOnce.Do(func() {
+	uninstallLoop:
+		for {
+			select {
+			case sub.es.uninstall <- sub.f:
+				break uninstallLoop
+			case <-sub.f.logs:
+			case <-sub.f.hashes:
+			case <-sub.f.headers:
+			}
+		}
+		<-sub.Err()
+	})
+}
+
+func (es *EventSystem) subscribe(sub *subscription) *Subscription {
+	es.install <- sub
+	<-sub.installed
+	return &Subscription{ID: sub.id, f: sub, es: es}
+}
+
+// SubscribeLogs creates a subscription that fires for logs matching crit.
+func (es *EventSystem) SubscribeLogs(crit FilterCriteria) (*Subscription, error) {
+	sub := &subscription{
+		id:        rpc.NewID(),
+		typ:       LogsSubscription,
+		logsCrit:  crit,
+		created:   time.Now(),
+		logs:      make(chan []*types.Log),
+		hashes:    make(chan common.Hash),
+		headers:   make(chan *types.Header),
+		installed: make(chan struct{}),
+		err:       make(chan error),
+	}
+	return es.subscribe(sub), nil
+}
+
+// SubscribeNewHeads creates a subscription that fires for every new block
+// imported onto the canonical chain.
+func (es *EventSystem) SubscribeNewHeads(headers chan *types.Header) *Subscription {
+	sub := &subscription{
+		id:        rpc.NewID(),
+		typ:       BlocksSubscription,
+		created:   time.Now(),
+		logs:      make(chan []*types.Log),
+		hashes:    make(chan common.Hash),
+		headers:   headers,
+		installed: make(chan struct{}),
+		err:       make(chan error),
+	}
+	return es.subscribe(sub)
+}
+
+// SubscribePendingTxs creates a subscription that fires for every transaction
+// entering the local pool.
+func (es *EventSystem) SubscribePendingTxs(hashes chan common.Hash) *Subscription {
+	sub := &subscription{
+		id:        rpc.NewID(),
+		typ:       PendingTransactionsSubscription,
+		created:   time.Now(),
+		logs:      make(chan []*types.Log),
+		hashes:    hashes,
+		headers:   make(chan *types.Header),
+		installed: make(chan struct{}),
+		err:       make(chan error),
+	}
+	return es.subscribe(sub)
+}
+
+func (es *EventSystem) handleLogs(filters map[rpc.ID]*subscription, logs []*types.Log) {
+	if len(logs) == 0 {
+		return
+	}
+	for _, f := range filters {
+		if f.typ != LogsSubscription {
+			continue
+		}
+		from, to := big.NewInt(int64(f.logsCrit.FromBlock)), big.NewInt(int64(f.logsCrit.ToBlock))
+		if matched := filterLogs(logs, from, to, f.logsCrit.Addresses, f.logsCrit.Topics); len(matched) > 0 {
+			f.logs <- matched
+		}
+	}
+}
+
+func (es *EventSystem) handleChainEvent(filters map[rpc.ID]*subscription, ev core.ChainEvent) {
+	for _, f := range filters {
+		if f.typ == BlocksSubscription {
+			f.headers <- ev.Block.Header()
+		}
+	}
+}
+
+func (es *EventSystem) handleTxEvent(filters map[rpc.ID]*subscription, ev core.TxPreEvent) {
+	for _, f := range filters {
+		if f.typ == PendingTransactionsSubscription {
+			f.hashes <- ev.Tx.Hash()
+		}
+	}
+}
+
+// eventLoop (main goroutine) runs for the lifetime of the EventSystem,
+// fanning incoming feed events out to every installed subscription of the
+// matching type and servicing install/uninstall requests.
+func (es *EventSystem) eventLoop() {
+	defer func() {
+		es.txsSub.Unsubscribe()
+		es.logsSub.Unsubscribe()
+		es.rmLogsSub.Unsubscribe()
+		es.chainSub.Unsubscribe()
+	}()
+
+	index := make(map[rpc.ID]*subscription)
+	for {
+		select {
+		case ev := <-es.txsCh:
+			es.handleTxEvent(index, ev)
+		case ev := <-es.logsCh:
+			es.handleLogs(index, ev)
+		case ev := <-es.rmLogsCh:
+			es.handleLogs(index, ev.Logs)
+		case ev := <-es.chainCh:
+			es.handleChainEvent(index, ev)
+
+		case f := <-es.install:
+			index[f.id] = f
+			close(f.installed)
+
+		case f := <-es.uninstall:
+			delete(index, f.id)
+			close(f.err)
+
+		case <-es.txsSub.Err():
+			return
+		case <-es.logsSub.Err():
+			return
+		case <-es.rmLogsSub.Err():
+			return
+		case <-es.chainSub.Err():
+			return
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/expanse-org/go-expanse/common"
+	"github.com/expanse-org/go-expanse/core"
+	"github.com/expanse-org/go-expanse/core/types"
+	"github.com/expanse-org/go-expanse/internal/ethapi"
+	"github.com/expanse-org/go-expanse/rpc"
+)
+
+// Backend is what the tracing API needs: ethapi.Backend plus the two replay
+// entry points added alongside this package.
+type Backend interface {
+	ethapi.Backend
+	TraceTransaction(ctx context.Context, txHash common.Hash, config *TraceConfig) (interface{}, error)
+	TraceCall(ctx context.Context, msg core.Message, blockNr rpc.BlockNumber, config *TraceConfig) (interface{}, error)
+}
+
+// PublicTracerAPI exposes debug_traceTransaction, debug_traceBlock and
+// debug_traceCall.
+type PublicTracerAPI struct {
+	b Backend
+}
+
+// NewPublicTracerAPI creates a new tracing API backed by b.
+func NewPublicTracerAPI(b Backend) *PublicTracerAPI {
+	return &PublicTracerAPI{b: b}
+}
+
+// TraceTransaction returns the structured logs (or the output of whatever
+// tracer config selects) created during the execution of txHash.
+func (api *PublicTracerAPI) TraceTransaction(ctx context.Context, txHash common.Hash, config *TraceConfig) (interface{}, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.TraceTimeout(5*time.Second))
+	defer cancel()
+	return api.b.TraceTransaction(ctx, txHash, config)
+}
+
+// TraceBlockByNumber traces every transaction in the block at blockNr and
+// returns one result per transaction, in order.
+func (api *PublicTracerAPI) TraceBlockByNumber(ctx context.Context, blockNr rpc.BlockNumber, config *TraceConfig) ([]interface{}, error) {
+	block, err := api.b.BlockByNumber(ctx, blockNr)
+	if block == nil || err != nil {
+		return nil, err
+	}
+	return api.traceBlock(ctx, block, config)
+}
+
+// TraceBlockByHash traces every transaction in the block identified by hash.
+func (api *PublicTracerAPI) TraceBlockByHash(ctx context.Context, hash common.Hash, config *TraceConfig) ([]interface{}, error) {
+	block, err := api.b.GetBlock(ctx, hash)
+	if block == nil || err != nil {
+		return nil, err
+	}
+	return api.traceBlock(ctx, block, config)
+}
+
+func (api *PublicTracerAPI) traceBlock(ctx context.Context, block *types.Block, config *TraceConfig) ([]interface{}, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.TraceTimeout(30*time.Second))
+	defer cancel()
+
+	results := make([]interface{}, len(block.Transactions()))
+	for i, tx := range block.Transactions() {
+		res, err := api.b.TraceTransaction(ctx, tx.Hash(), config)
+		if err != nil {
+			return nil, fmt.Errorf("tracing tx %#x failed: %v", tx.Hash(), err)
+		}
+		results[i] = res
+	}
+	return results, nil
+}
+
+// TraceCall runs the given call against the state at blockNr with the
+// configured tracer installed, without requiring the call to correspond to
+// any real transaction.
+func (api *PublicTracerAPI) TraceCall(ctx context.Context, args ethapi.CallArgs, blockNr rpc.BlockNumber, config *TraceConfig) (interface{}, error) {
+	ctx, cancel := context.WithTimeout(ctx, config.TraceTimeout(5*time.Second))
+	defer cancel()
+
+	msg, err := args.ToMessage(api.b.ChainConfig())
+	if err != nil {
+		return nil, err
+	}
+	return api.b.TraceCall(ctx, msg, blockNr, config)
+}
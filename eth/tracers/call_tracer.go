@@ -0,0 +1,171 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"math/big"
+
+	"github.com/expanse-org/go-expanse/common"
+	"github.com/expanse-org/go-expanse/core/vm"
+)
+
+// CallFrame is a single entry of the tree callTracer produces: a CALL/CREATE
+// (or any of their variants) together with its nested sub-calls.
+type CallFrame struct {
+	Type    string         `json:"type"`
+	From    common.Address `json:"from"`
+	To      common.Address `json:"to,omitempty"`
+	Value   *big.Int       `json:"value,omitempty"`
+	Gas     uint64         `json:"gas"`
+	GasUsed uint64         `json:"gasUsed"`
+	Input   []byte         `json:"input"`
+	Output  []byte         `json:"output,omitempty"`
+	Error   string         `json:"error,omitempty"`
+	Calls   []*CallFrame   `json:"calls,omitempty"`
+
+	// closed is set once GasUsed (and, when available, Output) has been
+	// filled in for this frame, so a later exceptional halt doesn't
+	// overwrite an already-accurate value computed at its own RETURN/REVERT.
+	closed bool
+}
+
+// callTracer folds the CALL/CREATE family of opcodes into a nested call tree
+// instead of the flat opcode-by-opcode log the default tracer produces,
+// mirroring the "callTracer" preset debug_traceTransaction has long shipped.
+//
+// vm.Tracer only calls CaptureStart/CaptureEnd once per transaction, at
+// depth 0 (see EVM.Call/Create) — it has no per-nested-call enter/exit hook.
+// So every frame below the root is opened in CaptureState on seeing a
+// CALL-family/CREATE opcode, and closed here too: each CaptureState report
+// carries the interpreter's current depth, and a drop in depth since the
+// previous report means every frame between the two has already returned.
+type callTracer struct {
+	root  *CallFrame
+	stack []*CallFrame
+}
+
+func newCallTracer() *callTracer {
+	return &callTracer{}
+}
+
+// CaptureStart implements vm.Tracer, opening the outermost frame.
+func (t *callTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	typ := "CALL"
+	if create {
+		typ = "CREATE"
+	}
+	t.root = &CallFrame{Type: typ, From: from, To: to, Value: value, Gas: gas, Input: input}
+	t.stack = []*CallFrame{t.root}
+	return nil
+}
+
+// CaptureState implements vm.Tracer. It closes every frame the interpreter
+// has returned from since the previous report (depth now lower than the
+// stack), then opens a new nested frame whenever the current opcode is a
+// CALL-family/CREATE call, using the stack arguments the EVM is about to
+// act on. RETURN/REVERT are captured here too, since they're the only place
+// the frame's own return data is ever visible.
+func (t *callTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	// depth == len(t.stack)-1 while a frame is still executing; anything
+	// less means frames opened since then have already returned.
+	for depth < len(t.stack)-1 {
+		t.closeFrame(t.stack[len(t.stack)-1], gas, false)
+		t.stack = t.stack[:len(t.stack)-1]
+	}
+
+	switch op {
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		data := stack.Data()
+		frame := &CallFrame{Type: op.String(), Gas: gas}
+		// stack top is gas, the callee address is the second item.
+		if len(data) >= 2 {
+			frame.To = common.BigToAddress(data[len(data)-2])
+		}
+		parent := t.stack[len(t.stack)-1]
+		parent.Calls = append(parent.Calls, frame)
+		t.stack = append(t.stack, frame)
+	case vm.CREATE, vm.CREATE2:
+		// The contract address doesn't exist on the stack pre-execution, and
+		// CaptureEnd only fires for the root frame, so a nested CREATE
+		// frame's To is left empty.
+		frame := &CallFrame{Type: op.String(), Gas: gas}
+		parent := t.stack[len(t.stack)-1]
+		parent.Calls = append(parent.Calls, frame)
+		t.stack = append(t.stack, frame)
+	case vm.RETURN, vm.REVERT:
+		// Stack top is offset, second is size (EVM pops offset then size).
+		data := stack.Data()
+		if top := t.stack[len(t.stack)-1]; len(data) >= 2 {
+			offset, size := data[len(data)-1].Int64(), data[len(data)-2].Int64()
+			t.closeFrame(top, gas, true)
+			top.Output = memory.GetPtr(offset, size)
+			if op == vm.REVERT {
+				top.Error = "execution reverted"
+			}
+		}
+	case vm.STOP, vm.SELFDESTRUCT:
+		// Graceful halts with no return data of their own.
+		t.closeFrame(t.stack[len(t.stack)-1], gas, true)
+	}
+	return nil
+}
+
+// closeFrame fills in frame.GasUsed once, the first time it is observed
+// returning. If the frame reached RETURN/REVERT/STOP/SELFDESTRUCT itself,
+// GasUsed is frame.Gas minus the gas left at that opcode; for a nested frame
+// frame.Gas is the caller's gas when the call opcode ran, not the gas the
+// callee was given, so this overstates the callee's own use. If depth dropped
+// past the frame without such an opcode, GasUsed is all of frame.Gas.
+func (t *callTracer) closeFrame(frame *CallFrame, gas uint64, ownReturn bool) {
+	if frame.closed {
+		return
+	}
+	if ownReturn {
+		frame.GasUsed = frame.Gas - gas
+	} else {
+		frame.GasUsed = frame.Gas
+	}
+	frame.closed = true
+}
+
+// CaptureFault implements vm.Tracer, recording the error of whichever frame
+// is currently on top of the stack — the frame the fault actually happened
+// in, not necessarily the root.
+func (t *callTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	if len(t.stack) > 0 {
+		t.stack[len(t.stack)-1].Error = err.Error()
+	}
+	return nil
+}
+
+// CaptureEnd implements vm.Tracer, closing the root frame with its return
+// data, gas used and error (if any). This is the only frame CaptureEnd ever
+// fires for; every nested frame is closed from within CaptureState instead.
+func (t *callTracer) CaptureEnd(output []byte, gasUsed uint64, duration interface{}, err error) error {
+	t.root.Output = output
+	t.root.GasUsed = gasUsed
+	t.root.closed = true
+	if err != nil {
+		t.root.Error = err.Error()
+	}
+	return nil
+}
+
+// GetResult implements Tracer, returning the assembled call tree.
+func (t *callTracer) GetResult() (interface{}, error) {
+	return t.root, nil
+}
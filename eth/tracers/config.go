@@ -0,0 +1,51 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import "time"
+
+// TraceConfig holds the RPC-level configuration of a
+// debug_traceTransaction/debug_traceBlock/debug_traceCall call: which tracer
+// to run and the deadline of the context the trace runs under. The full-node
+// backend (eth.EthApiBackend) does not consult that context while executing,
+// so there the deadline does not stop a trace in progress.
+type TraceConfig struct {
+	Tracer  *string
+	Timeout *string
+}
+
+// TracerName returns the configured tracer's name (the empty string
+// selecting the default struct logger), treating a nil config the same way.
+func (c *TraceConfig) TracerName() string {
+	if c == nil || c.Tracer == nil {
+		return ""
+	}
+	return *c.Tracer
+}
+
+// TraceTimeout returns the configured timeout, or def if none was set or it
+// fails to parse.
+func (c *TraceConfig) TraceTimeout(def time.Duration) time.Duration {
+	if c == nil || c.Timeout == nil {
+		return def
+	}
+	d, err := time.ParseDuration(*c.Timeout)
+	if err != nil {
+		return def
+	}
+	return d
+}
@@ -0,0 +1,148 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/expanse-org/go-expanse/common"
+	"github.com/expanse-org/go-expanse/core/vm"
+	"github.com/robertkrimen/otto"
+)
+
+// jsTracer runs a user-supplied JavaScript tracer: a "step(log, db)"
+// function invoked once per executed opcode and a "result(ctx, db)" function
+// invoked once the call has finished. The db argument is always passed as
+// null.
+type jsTracer struct {
+	vm     *otto.Otto
+	step   otto.Value
+	fault  otto.Value
+	result otto.Value
+	ctx    map[string]interface{}
+	err    error
+}
+
+// newJsTracer compiles code (the body of an object literal defining step,
+// fault and result) and returns a Tracer that evaluates it once per opcode.
+func newJsTracer(code string) (*jsTracer, error) {
+	vmach := otto.New()
+	if _, err := vmach.Run("var tracer = (function() { return " + code + "})();"); err != nil {
+		return nil, fmt.Errorf("tracers: failed to compile tracer: %v", err)
+	}
+	tracerObj, err := vmach.Get("tracer")
+	if err != nil {
+		return nil, err
+	}
+	step, _ := tracerObj.Object().Get("step")
+	fault, _ := tracerObj.Object().Get("fault")
+	result, _ := tracerObj.Object().Get("result")
+	return &jsTracer{vm: vmach, step: step, fault: fault, result: result, ctx: make(map[string]interface{})}, nil
+}
+
+// CaptureStart implements vm.Tracer, recording the top-level call context
+// that result(ctx) can later read.
+func (jst *jsTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	jst.ctx["type"] = "CALL"
+	if create {
+		jst.ctx["type"] = "CREATE"
+	}
+	jst.ctx["from"] = from.Hex()
+	jst.ctx["to"] = to.Hex()
+	jst.ctx["input"] = fmt.Sprintf("%x", input)
+	jst.ctx["gas"] = gas
+	return nil
+}
+
+// CaptureState implements vm.Tracer by calling the user's step(log, db)
+// function with a log object describing the opcode about to execute.
+func (jst *jsTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	if !jst.step.IsFunction() || jst.err != nil {
+		return nil
+	}
+	logObj, _ := jst.vm.Object(`({})`)
+	logObj.Set("pc", pc)
+	logObj.Set("op", op.String())
+	logObj.Set("gas", gas)
+	logObj.Set("gasCost", cost)
+	logObj.Set("depth", depth)
+	var stck []string
+	for _, v := range stack.Data() {
+		stck = append(stck, v.String())
+	}
+	logObj.Set("stack", stck)
+	if _, callErr := jst.step.Call(jst.step, logObj, nil); callErr != nil {
+		jst.err = callErr
+	}
+	return nil
+}
+
+// CaptureFault implements vm.Tracer, invoking the user's fault(log, db) hook.
+func (jst *jsTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	if jst.fault.IsFunction() {
+		logObj, _ := jst.vm.Object(`({})`)
+		logObj.Set("pc", pc)
+		logObj.Set("op", op.String())
+		logObj.Set("error", err.Error())
+		jst.fault.Call(jst.fault, logObj, nil)
+	}
+	return nil
+}
+
+// CaptureEnd implements vm.Tracer, remembering the call's return data/error
+// for GetResult's ctx argument.
+func (jst *jsTracer) CaptureEnd(output []byte, gasUsed uint64, duration interface{}, err error) error {
+	jst.ctx["output"] = fmt.Sprintf("%x", output)
+	jst.ctx["gasUsed"] = gasUsed
+	if err != nil {
+		jst.ctx["error"] = err.Error()
+	}
+	return nil
+}
+
+// GetResult implements Tracer by invoking the user's result(ctx, db)
+// function and JSON round-tripping its return value into a plain Go value.
+func (jst *jsTracer) GetResult() (interface{}, error) {
+	if jst.err != nil {
+		return nil, jst.err
+	}
+	if !jst.result.IsFunction() {
+		return nil, nil
+	}
+	ctxObj, _ := jst.vm.ToValue(jst.ctx)
+	res, err := jst.result.Call(jst.result, ctxObj, nil)
+	if err != nil {
+		return nil, err
+	}
+	exported, err := res.Export()
+	if err != nil {
+		return nil, err
+	}
+	// Round-trip through JSON so the result is a plain Go value, consistent
+	// with what the native tracers return.
+	raw, err := json.Marshal(exported)
+	if err != nil {
+		return nil, err
+	}
+	var out interface{}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package tracers runs caller-supplied JavaScript transaction tracers and
+// provides the native Go "struct logger" and "call tracer" built-ins,
+// selectable by name from debug_traceTransaction/debug_traceCall.
+package tracers
+
+import (
+	"fmt"
+
+	"github.com/expanse-org/go-expanse/core/vm"
+)
+
+// Tracer is implemented by every tracer this package can produce: it behaves
+// like a vm.Tracer during execution and, once the call has finished, reports
+// a single JSON-marshalable result.
+type Tracer interface {
+	vm.Tracer
+	GetResult() (interface{}, error)
+}
+
+// New returns the tracer identified by code: the empty string for the
+// built-in struct logger, "callTracer" for the nested call-frame tracer, or
+// any other string is treated as the body of a JavaScript tracer function.
+func New(code string) (Tracer, error) {
+	switch code {
+	case "":
+		return newStructLoggerTracer(nil), nil
+	case "callTracer":
+		return newCallTracer(), nil
+	default:
+		return newJsTracer(code)
+	}
+}
+
+// structLoggerTracer adapts vm.StructLogger, which predates this package, to
+// the Tracer interface by formatting its accumulated log as the classic
+// debug_traceTransaction result shape.
+type structLoggerTracer struct {
+	*vm.StructLogger
+}
+
+func newStructLoggerTracer(cfg *vm.LogConfig) *structLoggerTracer {
+	return &structLoggerTracer{vm.NewStructLogger(cfg)}
+}
+
+// ExecutionResult groups the struct logger's step-by-step trace with the
+// call's overall gas usage and return data/error, matching what
+// debug_traceTransaction has always returned for the default tracer.
+type ExecutionResult struct {
+	Gas         uint64       `json:"gas"`
+	Failed      bool         `json:"failed"`
+	ReturnValue string       `json:"returnValue"`
+	StructLogs  []StructLogRes `json:"structLogs"`
+}
+
+// StructLogRes is the JSON form of a single vm.StructLog entry.
+type StructLogRes struct {
+	Pc      uint64            `json:"pc"`
+	Op      string            `json:"op"`
+	Gas     uint64            `json:"gas"`
+	GasCost uint64            `json:"gasCost"`
+	Depth   int               `json:"depth"`
+	Error   string            `json:"error,omitempty"`
+	Stack   []string          `json:"stack,omitempty"`
+	Memory  []string          `json:"memory,omitempty"`
+	Storage map[string]string `json:"storage,omitempty"`
+}
+
+func (t *structLoggerTracer) GetResult() (interface{}, error) {
+	logs := make([]StructLogRes, len(t.StructLogs()))
+	for i, log := range t.StructLogs() {
+		logs[i] = StructLogRes{
+			Pc:      log.Pc,
+			Op:      log.Op.String(),
+			Gas:     log.Gas,
+			GasCost: log.GasCost,
+			Depth:   log.Depth,
+		}
+		if log.Err != nil {
+			logs[i].Error = log.Err.Error()
+		}
+		for _, v := range log.Stack {
+			logs[i].Stack = append(logs[i].Stack, fmt.Sprintf("%x", v))
+		}
+		for offset := 0; offset+32 <= len(log.Memory); offset += 32 {
+			logs[i].Memory = append(logs[i].Memory, fmt.Sprintf("%x", log.Memory[offset:offset+32]))
+		}
+		if len(log.Storage) > 0 {
+			logs[i].Storage = make(map[string]string, len(log.Storage))
+			for key, value := range log.Storage {
+				logs[i].Storage[fmt.Sprintf("%x", key)] = fmt.Sprintf("%x", value)
+			}
+		}
+	}
+	return &ExecutionResult{
+		Gas:         t.GasUsed(),
+		Failed:      t.Error() != nil,
+		ReturnValue: fmt.Sprintf("%x", t.Output()),
+		StructLogs:  logs,
+	}, nil
+}
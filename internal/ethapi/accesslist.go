@@ -0,0 +1,104 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/expanse-org/go-expanse/common"
+	"github.com/expanse-org/go-expanse/common/hexutil"
+	"github.com/expanse-org/go-expanse/core"
+	"github.com/expanse-org/go-expanse/core/types"
+	"github.com/expanse-org/go-expanse/core/vm"
+	"github.com/expanse-org/go-expanse/rpc"
+)
+
+// accessListMaxIterations bounds how many times the call is re-run while the
+// suggested access list keeps growing, so a pathological call (e.g. one that
+// touches a different storage slot on every retry) can't hang the RPC.
+const accessListMaxIterations = 8
+
+// AccessListResult is the result of eth_createAccessList.
+type AccessListResult struct {
+	Accesslist *types.AccessList `json:"accessList"`
+	Error      string            `json:"error,omitempty"`
+	GasUsed    hexutil.Uint64    `json:"gasUsed"`
+}
+
+// CreateAccessList runs args against the state at blockNr, discovering the
+// set of addresses/storage slots it touches with a vm.AccessListTracer and
+// re-executing with the tracer seeded with that list, repeating until the
+// list stops growing. If it is still growing after accessListMaxIterations
+// runs, an error is returned. Every run executes the message built from args;
+// the discovered list is not added to that message, so GasUsed, the gas the
+// call spent in the final, stable run, includes no access-list charge.
+func (s *PublicBlockChainAPI) CreateAccessList(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber) (*AccessListResult, error) {
+	state, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	msg, err := args.ToMessage(s.b.ChainConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	// CreateAccessList re-runs args once per candidate access list. state must
+	// not be mutated by one trial and reused by the next — each iteration
+	// gets its own copy, so a multi-pass call (nonce/balance already debited
+	// by an earlier trial) doesn't corrupt the access list and gas usage
+	// reported for the next.
+	stateCopier, canCopy := state.(interface{ Copy() State })
+
+	var acl types.AccessList
+	for i := 0; i < accessListMaxIterations; i++ {
+		var to common.Address
+		if msg.To() != nil {
+			to = *msg.To()
+		}
+		tracer := vm.NewAccessListTracer(acl, msg.From(), to, nil)
+
+		trialState := state
+		if canCopy {
+			trialState = stateCopier.Copy()
+		}
+		evm, vmError, err := s.b.GetEVM(ctx, msg, trialState, header, vm.Config{Tracer: tracer, Debug: true})
+		if err != nil {
+			return nil, err
+		}
+		gp := new(core.GasPool).AddGas(msg.Gas())
+		_, gasUsed, failed, err := core.NewStateTransition(evm, msg, gp).TransitionDb()
+		if vmErr := vmError(); vmErr != nil {
+			return nil, vmErr
+		}
+
+		next := tracer.AccessList()
+		result := &AccessListResult{Accesslist: &next, GasUsed: hexutil.Uint64(gasUsed)}
+		if failed {
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				result.Error = "execution reverted"
+			}
+		}
+		if next.Equal(acl) {
+			return result, nil
+		}
+		acl = next
+	}
+	return nil, errors.New("eth_createAccessList: access list did not converge")
+}
@@ -0,0 +1,69 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"context"
+
+	"github.com/expanse-org/go-expanse/common"
+	"github.com/expanse-org/go-expanse/common/hexutil"
+	"github.com/expanse-org/go-expanse/rpc"
+)
+
+// StorageResult is the proof for a single storage slot, as returned inside
+// AccountResult.StorageProof by eth_getProof.
+type StorageResult struct {
+	Key   string       `json:"key"`
+	Value *hexutil.Big `json:"value"`
+	Proof []string     `json:"proof"`
+}
+
+// AccountResult is the EIP-1186 response shape for eth_getProof: the proven
+// account fields plus the account proof and, for each requested slot, its
+// storage proof.
+type AccountResult struct {
+	Address      common.Address  `json:"address"`
+	AccountProof []string        `json:"accountProof"`
+	Balance      *hexutil.Big    `json:"balance"`
+	CodeHash     common.Hash     `json:"codeHash"`
+	Nonce        hexutil.Uint64  `json:"nonce"`
+	StorageHash  common.Hash     `json:"storageHash"`
+	StorageProof []StorageResult `json:"storageProof"`
+}
+
+// GetProof returns the account and storage values of the specified account,
+// including the Merkle proof, at the given block number. It implements
+// eth_getProof (EIP-1186).
+func (s *PublicBlockChainAPI) GetProof(ctx context.Context, address common.Address, storageKeys []string, blockNr rpc.BlockNumber) (*AccountResult, error) {
+	state, _, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	proofer, ok := state.(interface {
+		GetProof(ctx context.Context, addr common.Address, storageKeys []string) (*AccountResult, error)
+	})
+	if !ok {
+		return nil, errNoProofSupport
+	}
+	return proofer.GetProof(ctx, address, storageKeys)
+}
+
+var errNoProofSupport = &proofError{"backend state does not support eth_getProof"}
+
+type proofError struct{ msg string }
+
+func (e *proofError) Error() string { return e.msg }
@@ -0,0 +1,274 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package les
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/expanse-org/go-expanse/accounts"
+	"github.com/expanse-org/go-expanse/common"
+	"github.com/expanse-org/go-expanse/common/math"
+	"github.com/expanse-org/go-expanse/core"
+	"github.com/expanse-org/go-expanse/core/types"
+	"github.com/expanse-org/go-expanse/core/vm"
+	"github.com/expanse-org/go-expanse/eth/downloader"
+	"github.com/expanse-org/go-expanse/eth/gasprice"
+	"github.com/expanse-org/go-expanse/eth/tracers"
+	"github.com/expanse-org/go-expanse/ethdb"
+	"github.com/expanse-org/go-expanse/event"
+	"github.com/expanse-org/go-expanse/internal/ethapi"
+	"github.com/expanse-org/go-expanse/light"
+	"github.com/expanse-org/go-expanse/params"
+	"github.com/expanse-org/go-expanse/rpc"
+)
+
+// LesApiBackend implements ethapi.Backend for the light client. It mirrors
+// eth.EthApiBackend method for method, but every accessor that would touch
+// local chain/state data instead resolves it on demand through les.odr,
+// blocking until a peer answers or ctx is cancelled.
+type LesApiBackend struct {
+	les *LightEthereum
+	gpo *gasprice.Oracle
+}
+
+func (b *LesApiBackend) ChainConfig() *params.ChainConfig {
+	return b.les.chainConfig
+}
+
+func (b *LesApiBackend) CurrentBlock() *types.Block {
+	return types.NewBlockWithHeader(b.les.blockchain.CurrentHeader())
+}
+
+func (b *LesApiBackend) SetHead(number uint64) {
+	b.les.blockchain.SetHead(number)
+}
+
+func (b *LesApiBackend) HeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Header, error) {
+	// The light client has no miner, so pending requests fall back to latest.
+	if blockNr == rpc.LatestBlockNumber || blockNr == rpc.PendingBlockNumber {
+		return b.les.blockchain.CurrentHeader(), nil
+	}
+	return b.les.blockchain.GetHeaderByNumberOdr(ctx, uint64(blockNr))
+}
+
+func (b *LesApiBackend) BlockByNumber(ctx context.Context, blockNr rpc.BlockNumber) (*types.Block, error) {
+	header, err := b.HeaderByNumber(ctx, blockNr)
+	if header == nil || err != nil {
+		return nil, err
+	}
+	return b.GetBlock(ctx, header.Hash())
+}
+
+func (b *LesApiBackend) StateAndHeaderByNumber(ctx context.Context, blockNr rpc.BlockNumber) (ethapi.State, *types.Header, error) {
+	header, err := b.HeaderByNumber(ctx, blockNr)
+	if header == nil || err != nil {
+		return nil, nil, err
+	}
+	state, err := light.NewState(ctx, header, b.les.odr)
+	if err != nil {
+		return nil, nil, err
+	}
+	return state, header, nil
+}
+
+func (b *LesApiBackend) GetBlock(ctx context.Context, blockHash common.Hash) (*types.Block, error) {
+	return b.les.blockchain.GetBlockByHashOdr(ctx, blockHash)
+}
+
+func (b *LesApiBackend) GetReceipts(ctx context.Context, blockHash common.Hash) (types.Receipts, error) {
+	number := core.GetBlockNumber(b.les.chainDb, blockHash)
+	req := &light.ReceiptsRequest{Hash: blockHash, Number: number}
+	if err := b.les.odr.Retrieve(ctx, req); err != nil {
+		return nil, err
+	}
+	return req.Receipts, nil
+}
+
+func (b *LesApiBackend) GetTd(blockHash common.Hash) *big.Int {
+	return b.les.blockchain.GetTdByHash(blockHash)
+}
+
+func (b *LesApiBackend) GetEVM(ctx context.Context, msg core.Message, state ethapi.State, header *types.Header, vmCfg vm.Config) (*vm.EVM, func() error, error) {
+	statedb := state.(*light.State).StateDB()
+	from := statedb.GetOrNewStateObject(msg.From())
+	from.SetBalance(math.MaxBig256)
+	vmError := func() error { return statedb.Error() }
+
+	context := core.NewEVMContext(msg, header, b.les.blockchain, nil)
+	return vm.NewEVM(context, statedb, b.les.chainConfig, vmCfg), vmError, nil
+}
+
+// TraceTransaction replays the block containing txHash up to and including
+// that transaction, reconstructing its exact pre-state by re-executing every
+// earlier transaction with a no-op tracer against ODR-backed state, then
+// re-runs txHash itself with the tracer selected by config. It mirrors
+// eth.EthApiBackend.TraceTransaction, substituting ODR retrieval for direct
+// blockchain/state access.
+func (b *LesApiBackend) TraceTransaction(ctx context.Context, txHash common.Hash, config *tracers.TraceConfig) (interface{}, error) {
+	tx, blockHash, _, index := core.GetTransaction(b.les.chainDb, txHash)
+	if tx == nil {
+		return nil, fmt.Errorf("transaction %#x not found", txHash)
+	}
+	block, err := b.les.blockchain.GetBlockByHashOdr(ctx, blockHash)
+	if err != nil || block == nil {
+		return nil, fmt.Errorf("block %#x not found", blockHash)
+	}
+	parentHeader, err := b.les.blockchain.GetHeaderByNumberOdr(ctx, block.NumberU64()-1)
+	if err != nil || parentHeader == nil {
+		return nil, fmt.Errorf("parent of block %#x not found", blockHash)
+	}
+	state, err := light.NewState(ctx, parentHeader, b.les.odr)
+	if err != nil {
+		return nil, err
+	}
+	statedb := state.StateDB()
+
+	signer := types.MakeSigner(b.les.chainConfig, block.Number())
+	for i, txn := range block.Transactions() {
+		msg, err := txn.AsMessage(signer)
+		if err != nil {
+			return nil, err
+		}
+		context := core.NewEVMContext(msg, block.Header(), b.les.blockchain, nil)
+
+		if uint64(i) == index {
+			tracer, err := tracers.New(config.TracerName())
+			if err != nil {
+				return nil, err
+			}
+			vmenv := vm.NewEVM(context, statedb, b.les.chainConfig, vm.Config{Tracer: tracer, Debug: true})
+			if _, _, _, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.Gas())); err != nil {
+				return nil, fmt.Errorf("tracing failed: %v", err)
+			}
+			return tracer.GetResult()
+		}
+
+		vmenv := vm.NewEVM(context, statedb, b.les.chainConfig, vm.Config{})
+		if _, _, _, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.Gas())); err != nil {
+			return nil, fmt.Errorf("transaction %#x failed: %v", txn.Hash(), err)
+		}
+		statedb.Finalise(true)
+	}
+	return nil, fmt.Errorf("transaction index %d not found in block %#x", index, blockHash)
+}
+
+// TraceCall runs msg against the ODR-backed state at blockNr with the tracer
+// selected by config installed, instead of applying the resulting state
+// changes. It mirrors eth.EthApiBackend.TraceCall.
+func (b *LesApiBackend) TraceCall(ctx context.Context, msg core.Message, blockNr rpc.BlockNumber, config *tracers.TraceConfig) (interface{}, error) {
+	state, header, err := b.StateAndHeaderByNumber(ctx, blockNr)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	tracer, err := tracers.New(config.TracerName())
+	if err != nil {
+		return nil, err
+	}
+	evm, vmError, err := b.GetEVM(ctx, msg, state, header, vm.Config{Tracer: tracer, Debug: true})
+	if err != nil {
+		return nil, err
+	}
+	if _, _, _, err := core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(msg.Gas())); err != nil {
+		return nil, fmt.Errorf("tracing failed: %v", err)
+	}
+	if err := vmError(); err != nil {
+		return nil, err
+	}
+	return tracer.GetResult()
+}
+
+// SubscribeRemovedLogsEvent, SubscribeChainEvent, SubscribeChainHeadEvent,
+// SubscribeChainSideEvent, SubscribeLogsEvent and SubscribeTxPreEvent below
+// mirror eth.EthApiBackend's subscription surface method for method, so that
+// eth/filters works unmodified against either backend.
+func (b *LesApiBackend) SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription {
+	return b.les.blockchain.SubscribeRemovedLogsEvent(ch)
+}
+
+func (b *LesApiBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription {
+	return b.les.blockchain.SubscribeChainEvent(ch)
+}
+
+func (b *LesApiBackend) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
+	return b.les.blockchain.SubscribeChainHeadEvent(ch)
+}
+
+func (b *LesApiBackend) SubscribeChainSideEvent(ch chan<- core.ChainSideEvent) event.Subscription {
+	return b.les.blockchain.SubscribeChainSideEvent(ch)
+}
+
+func (b *LesApiBackend) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription {
+	return b.les.blockchain.SubscribeLogsEvent(ch)
+}
+
+func (b *LesApiBackend) SubscribeTxPreEvent(ch chan<- core.TxPreEvent) event.Subscription {
+	return b.les.txPool.SubscribeTxPreEvent(ch)
+}
+
+func (b *LesApiBackend) SendTx(ctx context.Context, signedTx *types.Transaction) error {
+	return b.les.txPool.Add(ctx, signedTx)
+}
+
+func (b *LesApiBackend) RemoveTx(txHash common.Hash) {
+	b.les.txPool.RemoveTx(txHash)
+}
+
+func (b *LesApiBackend) GetPoolTransactions() (types.Transactions, error) {
+	return b.les.txPool.GetTransactions(), nil
+}
+
+func (b *LesApiBackend) GetPoolTransaction(txHash common.Hash) *types.Transaction {
+	return b.les.txPool.GetTransaction(txHash)
+}
+
+func (b *LesApiBackend) GetPoolNonce(ctx context.Context, addr common.Address) (uint64, error) {
+	return b.les.txPool.GetNonce(ctx, addr)
+}
+
+func (b *LesApiBackend) Stats() (pending int, queued int) {
+	return b.les.txPool.Stats(), 0
+}
+
+func (b *LesApiBackend) TxPoolContent() (map[common.Address]types.Transactions, map[common.Address]types.Transactions) {
+	return b.les.txPool.Content()
+}
+
+func (b *LesApiBackend) Downloader() *downloader.Downloader {
+	return nil
+}
+
+func (b *LesApiBackend) ProtocolVersion() int {
+	return ProtocolVersion
+}
+
+func (b *LesApiBackend) SuggestPrice(ctx context.Context) (*big.Int, error) {
+	return b.gpo.SuggestPrice(ctx)
+}
+
+func (b *LesApiBackend) ChainDb() ethdb.Database {
+	return b.les.chainDb
+}
+
+func (b *LesApiBackend) EventMux() *event.TypeMux {
+	return b.les.EventMux()
+}
+
+func (b *LesApiBackend) AccountManager() *accounts.Manager {
+	return b.les.accountManager
+}
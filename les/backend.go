@@ -0,0 +1,207 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package les implements the Light Expanse Subprotocol.
+package les
+
+import (
+	"fmt"
+
+	"github.com/expanse-org/go-expanse/accounts"
+	"github.com/expanse-org/go-expanse/common"
+	"github.com/expanse-org/go-expanse/core"
+	"github.com/expanse-org/go-expanse/core/types"
+	"github.com/expanse-org/go-expanse/eth"
+	"github.com/expanse-org/go-expanse/eth/gasprice"
+	"github.com/expanse-org/go-expanse/ethdb"
+	"github.com/expanse-org/go-expanse/event"
+	"github.com/expanse-org/go-expanse/internal/ethapi"
+	"github.com/expanse-org/go-expanse/light"
+	"github.com/expanse-org/go-expanse/log"
+	"github.com/expanse-org/go-expanse/node"
+	"github.com/expanse-org/go-expanse/p2p"
+	"github.com/expanse-org/go-expanse/params"
+	"github.com/expanse-org/go-expanse/rpc"
+)
+
+// LightEthereum implements the Expanse light client service. It connects to
+// full nodes over the les sub-protocol and serves internal/ethapi through
+// LesApiBackend instead of holding the full chain state locally.
+type LightEthereum struct {
+	chainConfig    *params.ChainConfig
+	chainDb        ethdb.Database
+	accountManager *accounts.Manager
+
+	networkId uint64
+	genesis   common.Hash
+
+	odr        *LesOdr
+	peers      *peerSet
+	blockchain *light.LightChain
+	txPool     *light.TxPool
+
+	ApiBackend *LesApiBackend
+}
+
+// New creates the light Expanse service.
+func New(ctx *node.ServiceContext, config *eth.Config) (*LightEthereum, error) {
+	chainDb, err := ctx.OpenDatabase("lightchaindata", config.DatabaseCache, config.DatabaseHandles)
+	if err != nil {
+		return nil, err
+	}
+	chainConfig, genesisHash, genesisErr := core.SetupGenesisBlock(chainDb, config.Genesis)
+	if genesisErr != nil {
+		return nil, genesisErr
+	}
+
+	peers := newPeerSet()
+	leth := &LightEthereum{
+		chainConfig:    chainConfig,
+		chainDb:        chainDb,
+		accountManager: ctx.AccountManager,
+		networkId:      config.NetworkId,
+		genesis:        genesisHash,
+		peers:          peers,
+	}
+	leth.odr = NewLesOdr(chainDb, nil, nil, nil, peers)
+	leth.blockchain, err = light.NewLightChain(leth.odr, chainConfig)
+	if err != nil {
+		return nil, err
+	}
+	leth.txPool = light.NewTxPool(chainConfig, leth.blockchain, leth.relay)
+
+	leth.ApiBackend = &LesApiBackend{leth, nil}
+	leth.ApiBackend.gpo = gasprice.NewOracle(leth.ApiBackend, config.GPO)
+	return leth, nil
+}
+
+// relay forwards a locally signed transaction to the best connected peer,
+// since a light client cannot include it in a block itself.
+func (s *LightEthereum) relay(tx *types.Transaction) error {
+	peer := s.peers.bestPeer()
+	if peer == nil {
+		return light.ErrNoPeers
+	}
+	return p2p.Send(peer.rw(), SendTxMsg, types.Transactions{tx})
+}
+
+// Protocols implements node.Service, registering the les sub-protocol with
+// the p2p server so it is offered during the devp2p handshake and runPeer is
+// invoked for every peer that negotiates it.
+func (s *LightEthereum) Protocols() []p2p.Protocol {
+	return []p2p.Protocol{
+		{
+			Name:    ProtocolName,
+			Version: ProtocolVersion,
+			Length:  ProtocolLengths[ProtocolVersion-1],
+			Run:     s.runPeer,
+		},
+	}
+}
+
+// APIs returns the collection of RPC services the light client exposes,
+// identical in shape to the full node's but backed by s.ApiBackend.
+func (s *LightEthereum) APIs() []rpc.API {
+	return ethapi.GetAPIs(s.ApiBackend)
+}
+
+// Start implements node.Service.
+func (s *LightEthereum) Start(srvr *p2p.Server) error { return nil }
+
+// runPeer is invoked by the p2p server as the Run function of the les
+// protocol for every connected peer that negotiated it: it performs the
+// les/1 handshake, registers the peer for the duration of the connection and
+// then services messages from it until it disconnects or the connection
+// errors out.
+func (s *LightEthereum) runPeer(p *p2p.Peer, rw p2p.MsgReadWriter) error {
+	peer := newPeer(p, rw)
+	head := s.blockchain.CurrentHeader()
+	td := s.blockchain.GetTdByHash(head.Hash())
+	if err := peer.Handshake(s.networkId, td, head.Hash(), s.genesis); err != nil {
+		log.Debug("les handshake failed", "peer", peer.id, "err", err)
+		return err
+	}
+	s.peers.register(peer)
+	defer s.peers.dropPeer(peer)
+
+	for {
+		if err := s.handleMsg(peer); err != nil {
+			log.Debug("les message handling failed", "peer", peer.id, "err", err)
+			return err
+		}
+	}
+}
+
+// handleMsg reads and dispatches a single incoming les/1 message from peer:
+// an Announce records the peer's new head and total difficulty and, only if
+// the ODR backend has a CHT indexer, retrieves the announced header and
+// advances the local chain head to it; every response message wakes up the
+// peer.retrieve call waiting on its request ID.
+func (s *LightEthereum) handleMsg(p *peer) error {
+	msg, err := p.rw().ReadMsg()
+	if err != nil {
+		return err
+	}
+	defer msg.Discard()
+
+	switch msg.Code {
+	case StatusMsg:
+		return fmt.Errorf("%w: unexpected StatusMsg after handshake", errResp)
+
+	case AnnounceMsg:
+		var announce announceData
+		if err := msg.Decode(&announce); err != nil {
+			return fmt.Errorf("%w: %v", errResp, err)
+		}
+		p.lock.Lock()
+		p.head, p.td = announce.Hash, announce.Td
+		p.lock.Unlock()
+		if chtIndexer := s.odr.ChtIndexer(); chtIndexer != nil {
+			req := &light.ChtRequest{ChtRoot: chtIndexer.SectionHead(announce.Number), Number: announce.Number}
+			go func() {
+				if err := s.odr.Retrieve(light.NoOdr, req); err == nil {
+					s.blockchain.AdvanceHead(req.Header)
+				}
+			}()
+		}
+
+	case BlockHeadersMsg, BlockBodiesMsg, ReceiptsMsg, ProofsMsg, ProofsV2Msg, HeaderProofsMsg, CodeMsg:
+		var resp respMsg
+		if err := msg.Decode(&resp); err != nil {
+			return fmt.Errorf("%w: %v", errResp, err)
+		}
+		p.deliver(resp.ReqID, resp.Data)
+
+	case SendTxMsg:
+		// A peer relaying a transaction to us; light clients don't serve
+		// other peers, so there is nothing to do with it.
+
+	default:
+		return fmt.Errorf("%w: unknown code %d", errResp, msg.Code)
+	}
+	return nil
+}
+
+// Stop implements node.Service.
+func (s *LightEthereum) Stop() error {
+	s.chainDb.Close()
+	return nil
+}
+
+func (s *LightEthereum) BlockChain() *light.LightChain      { return s.blockchain }
+func (s *LightEthereum) TxPool() *light.TxPool              { return s.txPool }
+func (s *LightEthereum) AccountManager() *accounts.Manager  { return s.accountManager }
+func (s *LightEthereum) EventMux() *event.TypeMux           { return nil }
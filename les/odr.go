@@ -0,0 +1,88 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package les
+
+import (
+	"context"
+
+	"github.com/expanse-org/go-expanse/ethdb"
+	"github.com/expanse-org/go-expanse/light"
+)
+
+// LesOdr implements light.OdrBackend by dispatching retrieval requests to
+// connected LES peers over the les/1 sub-protocol and blocking until one of
+// them answers or ctx is cancelled.
+type LesOdr struct {
+	db               ethdb.Database
+	chtIndexer       light.ChainIndexer
+	bloomTrieIndexer light.ChainIndexer
+	bloomIndexer     light.ChainIndexer
+	peers            *peerSet
+}
+
+// NewLesOdr creates an ODR backend that retrieves requests from peers and
+// caches their answers in db.
+func NewLesOdr(db ethdb.Database, chtIndexer, bloomTrieIndexer, bloomIndexer light.ChainIndexer, peers *peerSet) *LesOdr {
+	return &LesOdr{
+		db:               db,
+		chtIndexer:       chtIndexer,
+		bloomTrieIndexer: bloomTrieIndexer,
+		bloomIndexer:     bloomIndexer,
+		peers:            peers,
+	}
+}
+
+// Database implements light.OdrBackend.
+func (odr *LesOdr) Database() ethdb.Database { return odr.db }
+
+// ChtIndexer implements light.OdrBackend.
+func (odr *LesOdr) ChtIndexer() light.ChainIndexer { return odr.chtIndexer }
+
+// BloomTrieIndexer implements light.OdrBackend.
+func (odr *LesOdr) BloomTrieIndexer() light.ChainIndexer { return odr.bloomTrieIndexer }
+
+// BloomIndexer implements light.OdrBackend.
+func (odr *LesOdr) BloomIndexer() light.ChainIndexer { return odr.bloomIndexer }
+
+// Retrieve sends req to the connected peer with the highest reported total
+// difficulty. A peer the request cannot be sent to is dropped and the next
+// best one is tried, until one answers, no peer is left or ctx is done. An
+// answer that decodes is passed to req.StoreResult to be cached in db;
+// TrieRequest and ChtRequest check their proofs there and cache nothing that
+// fails the check. Retrieve returns after the first answer, without trying
+// another peer, whether or not the answer decoded or passed that check.
+func (odr *LesOdr) Retrieve(ctx context.Context, req light.OdrRequest) error {
+	for {
+		peer := odr.peers.bestPeer()
+		if peer == nil {
+			return light.ErrNoPeers
+		}
+		answered, err := peer.retrieve(ctx, req)
+		if answered {
+			if err == nil {
+				req.StoreResult(odr.db)
+			}
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+			odr.peers.dropPeer(peer)
+		}
+	}
+}
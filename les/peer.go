@@ -0,0 +1,247 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package les
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"sync/atomic"
+
+	"github.com/expanse-org/go-expanse/common"
+	"github.com/expanse-org/go-expanse/core/types"
+	"github.com/expanse-org/go-expanse/light"
+	"github.com/expanse-org/go-expanse/p2p"
+	"github.com/expanse-org/go-expanse/rlp"
+)
+
+// errNotAnswered is returned internally by peer.retrieve when ctx is done
+// before the peer answered, so the caller knows to try the next peer rather
+// than treating it as a final error.
+var errNotAnswered = errors.New("les: request not answered")
+
+// peer represents a remote node speaking the les sub-protocol.
+type peer struct {
+	*p2p.Peer
+	msgRW p2p.MsgReadWriter
+
+	id   string
+	head common.Hash
+	td   *big.Int
+
+	nextReqID uint64
+
+	lock     sync.RWMutex
+	requests map[uint64]*pendingRequest
+}
+
+// pendingRequest pairs an outstanding ODR request with the channel its
+// answer is delivered on, so deliver can decode the response straight into
+// the same req the caller is blocked on in retrieve.
+type pendingRequest struct {
+	req     light.OdrRequest
+	deliver chan odrResponse
+}
+
+// odrResponse is delivered on a peer's pending request channel once the
+// corresponding LES response message has been decoded.
+type odrResponse struct {
+	req light.OdrRequest
+	err error
+}
+
+// newPeer wraps p/rw as a les peer, ready for Handshake.
+func newPeer(p *p2p.Peer, rw p2p.MsgReadWriter) *peer {
+	return &peer{
+		Peer:     p,
+		msgRW:    rw,
+		id:       p.ID().String(),
+		requests: make(map[uint64]*pendingRequest),
+	}
+}
+
+// Handshake executes the les/1 status exchange: it sends our own head/td and
+// blocks until the remote side's StatusMsg arrives, rejecting the peer if it
+// is on a different network or genesis block.
+func (p *peer) Handshake(networkId uint64, td *big.Int, head, genesis common.Hash) error {
+	errc := make(chan error, 2)
+	var status statusData
+	go func() {
+		errc <- p2p.Send(p.rw(), StatusMsg, &statusData{
+			ProtocolVersion: ProtocolVersion,
+			NetworkId:       networkId,
+			TD:              td,
+			Head:            head,
+			Genesis:         genesis,
+		})
+	}()
+	go func() {
+		errc <- p.readStatus(&status)
+	}()
+	for i := 0; i < 2; i++ {
+		if err := <-errc; err != nil {
+			return err
+		}
+	}
+	if status.NetworkId != networkId {
+		return errNetworkIdMismatch
+	}
+	if status.Genesis != genesis {
+		return errGenesisMismatch
+	}
+	p.td, p.head = status.TD, status.Head
+	return nil
+}
+
+func (p *peer) readStatus(status *statusData) error {
+	msg, err := p.rw().ReadMsg()
+	if err != nil {
+		return err
+	}
+	defer msg.Discard()
+	if msg.Code != StatusMsg {
+		return fmt.Errorf("%w: first message code %d, want StatusMsg", errResp, msg.Code)
+	}
+	return msg.Decode(status)
+}
+
+// retrieve sends req to the peer and blocks until it answers or ctx expires.
+// The bool return reports whether the peer ever answered at all, so the
+// caller (LesOdr.Retrieve) can distinguish "try another peer" from "this
+// peer answered but the proof/data was invalid".
+func (p *peer) retrieve(ctx context.Context, req light.OdrRequest) (bool, error) {
+	deliver := make(chan odrResponse, 1)
+	reqID := p.addPending(req, deliver)
+	defer p.removePending(reqID)
+
+	if err := p.sendRequest(reqID, req); err != nil {
+		return false, err
+	}
+	select {
+	case resp := <-deliver:
+		return true, resp.err
+	case <-ctx.Done():
+		return false, errNotAnswered
+	}
+}
+
+func (p *peer) addPending(req light.OdrRequest, ch chan odrResponse) uint64 {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	reqID := atomic.AddUint64(&p.nextReqID, 1)
+	p.requests[reqID] = &pendingRequest{req: req, deliver: ch}
+	return reqID
+}
+
+func (p *peer) removePending(reqID uint64) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	delete(p.requests, reqID)
+}
+
+// sendRequest encodes and writes the LES request message matching req's
+// concrete type (GetBlockHeadersMsg, GetReceiptsMsg, GetProofsMsg, ...).
+func (p *peer) sendRequest(reqID uint64, req light.OdrRequest) error {
+	switch r := req.(type) {
+	case *light.TrieRequest:
+		return p2p.Send(p.rw(), GetProofsV2Msg, &reqMsg{ReqID: reqID, Data: r})
+	case *light.BlockRequest:
+		return p2p.Send(p.rw(), GetBlockBodiesMsg, &reqMsg{ReqID: reqID, Data: r.Hash})
+	case *light.ReceiptsRequest:
+		return p2p.Send(p.rw(), GetReceiptsMsg, &reqMsg{ReqID: reqID, Data: r.Hash})
+	case *light.ChtRequest:
+		return p2p.Send(p.rw(), GetHeaderProofsMsg, &reqMsg{ReqID: reqID, Data: r})
+	default:
+		return fmt.Errorf("les: unsupported odr request type %T", req)
+	}
+}
+
+// deliver is invoked by the protocol's message loop when a response to reqID
+// arrives. It decodes data into the concrete type of the pending req (so the
+// caller blocked in retrieve observes the populated fields) and wakes it up.
+func (p *peer) deliver(reqID uint64, data rlp.RawValue) {
+	p.lock.RLock()
+	pending, ok := p.requests[reqID]
+	p.lock.RUnlock()
+	if !ok {
+		return
+	}
+
+	var err error
+	switch r := pending.req.(type) {
+	case *light.TrieRequest:
+		var proof []light.ProofNode
+		err = rlp.DecodeBytes(data, &proof)
+		r.Proof = proof
+	case *light.BlockRequest:
+		err = rlp.DecodeBytes(data, &r.Rlp)
+	case *light.ReceiptsRequest:
+		err = rlp.DecodeBytes(data, &r.Receipts)
+	case *light.ChtRequest:
+		var reply struct {
+			Header *types.Header
+			Proof  []light.ProofNode
+		}
+		err = rlp.DecodeBytes(data, &reply)
+		r.Header = reply.Header
+		r.Proof = reply.Proof
+	default:
+		err = fmt.Errorf("les: unsupported odr request type %T", pending.req)
+	}
+	pending.deliver <- odrResponse{req: pending.req, err: err}
+}
+
+func (p *peer) rw() p2p.MsgReadWriter { return p.msgRW }
+
+// peerSet tracks the currently connected LES peers.
+type peerSet struct {
+	lock  sync.RWMutex
+	peers map[string]*peer
+}
+
+func newPeerSet() *peerSet {
+	return &peerSet{peers: make(map[string]*peer)}
+}
+
+// bestPeer returns the connected peer with the highest reported total
+// difficulty, or nil if none are connected.
+func (ps *peerSet) bestPeer() *peer {
+	ps.lock.RLock()
+	defer ps.lock.RUnlock()
+
+	var best *peer
+	for _, p := range ps.peers {
+		if best == nil || p.td.Cmp(best.td) > 0 {
+			best = p
+		}
+	}
+	return best
+}
+
+func (ps *peerSet) dropPeer(p *peer) {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+	delete(ps.peers, p.id)
+}
+
+func (ps *peerSet) register(p *peer) {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+	ps.peers[p.id] = p
+}
@@ -0,0 +1,63 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package les
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestPeerSetBestPeer(t *testing.T) {
+	ps := newPeerSet()
+	if ps.bestPeer() != nil {
+		t.Fatal("bestPeer on an empty set should be nil")
+	}
+
+	low := &peer{id: "low", td: big.NewInt(10), requests: make(map[uint64]*pendingRequest)}
+	high := &peer{id: "high", td: big.NewInt(20), requests: make(map[uint64]*pendingRequest)}
+	ps.register(low)
+	ps.register(high)
+
+	if best := ps.bestPeer(); best != high {
+		t.Fatalf("bestPeer = %v, want the peer with the higher td (%v)", best, high)
+	}
+
+	ps.dropPeer(high)
+	if best := ps.bestPeer(); best != low {
+		t.Fatalf("bestPeer after dropping the best = %v, want %v", best, low)
+	}
+}
+
+func TestPeerAddRemovePending(t *testing.T) {
+	p := &peer{id: "p", requests: make(map[uint64]*pendingRequest)}
+
+	ch1 := make(chan odrResponse, 1)
+	ch2 := make(chan odrResponse, 1)
+	id1 := p.addPending(nil, ch1)
+	id2 := p.addPending(nil, ch2)
+	if id1 == id2 {
+		t.Fatalf("addPending returned the same request ID twice: %d", id1)
+	}
+
+	p.removePending(id1)
+	if _, ok := p.requests[id1]; ok {
+		t.Fatal("removePending left the request in the map")
+	}
+	if _, ok := p.requests[id2]; !ok {
+		t.Fatal("removePending removed an unrelated request")
+	}
+}
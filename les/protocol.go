@@ -0,0 +1,101 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package les
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/expanse-org/go-expanse/common"
+	"github.com/expanse-org/go-expanse/rlp"
+)
+
+// errors returned while processing an incoming les/1 message.
+var (
+	errResp              = errors.New("les: invalid message")
+	errNetworkIdMismatch = errors.New("les: network id mismatch")
+	errGenesisMismatch   = errors.New("les: genesis block mismatch")
+)
+
+const (
+	// ProtocolName is the official short name of the les protocol used during
+	// devp2p capability negotiation.
+	ProtocolName = "les"
+
+	// ProtocolVersion is the latest supported version of the les protocol.
+	ProtocolVersion = 1
+)
+
+// ProtocolLengths is the number of implemented message codes for each
+// version of the les protocol, indexed by ProtocolVersion-1.
+var ProtocolLengths = []uint64{17}
+
+// les protocol message codes, mirroring the full eth/63 request/response
+// pairs plus the ODR-specific proof messages.
+const (
+	StatusMsg = iota
+	AnnounceMsg
+	GetBlockHeadersMsg
+	BlockHeadersMsg
+	GetBlockBodiesMsg
+	BlockBodiesMsg
+	GetReceiptsMsg
+	ReceiptsMsg
+	GetProofsMsg
+	ProofsMsg
+	GetCodeMsg
+	CodeMsg
+	SendTxMsg
+	GetHeaderProofsMsg
+	HeaderProofsMsg
+	GetProofsV2Msg
+	ProofsV2Msg
+)
+
+// statusData is the payload of the StatusMsg exchanged as the first message
+// on every les connection, establishing that both sides speak the same
+// protocol version on the same chain before any request is sent.
+type statusData struct {
+	ProtocolVersion uint32
+	NetworkId       uint64
+	TD              *big.Int
+	Head            common.Hash
+	Genesis         common.Hash
+}
+
+// announceData is the payload of an AnnounceMsg, sent whenever a peer's own
+// chain head advances so the light client knows there is a new head to
+// resolve headers/bodies/receipts against.
+type announceData struct {
+	Hash   common.Hash
+	Number uint64
+	Td     *big.Int
+}
+
+// reqMsg wraps an outgoing request with the request ID its response will
+// carry, so the response can be matched back to the peer.retrieve call that
+// is waiting on it.
+type reqMsg struct {
+	ReqID uint64
+	Data  interface{}
+}
+
+// respMsg wraps an incoming response with the request ID it answers.
+type respMsg struct {
+	ReqID uint64
+	Data  rlp.RawValue
+}
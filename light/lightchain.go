@@ -0,0 +1,251 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package light
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"sync"
+
+	"github.com/expanse-org/go-expanse/common"
+	"github.com/expanse-org/go-expanse/core"
+	"github.com/expanse-org/go-expanse/core/types"
+	"github.com/expanse-org/go-expanse/crypto"
+	"github.com/expanse-org/go-expanse/ethdb"
+	"github.com/expanse-org/go-expanse/event"
+	"github.com/expanse-org/go-expanse/params"
+	"github.com/expanse-org/go-expanse/rlp"
+	"github.com/expanse-org/go-expanse/trie"
+)
+
+// errInvalidBody is returned by GetBlockByHashOdr when a peer-supplied block
+// body does not hash to the transactions/uncles root recorded in the locally
+// held header.
+var errInvalidBody = errors.New("light: peer returned block body not matching header")
+
+// errUnprovenHeader is returned by GetHeaderByNumberOdr when the header a
+// peer returned is not proven by its CHT proof.
+var errUnprovenHeader = errors.New("light: peer returned header not proven against the CHT")
+
+// LightChain is a read-only view of the canonical chain maintained by a light
+// client. It holds only headers locally (received via the les announce
+// messages); everything else is fetched through odr on demand.
+type LightChain struct {
+	chainConfig *params.ChainConfig
+	odr         OdrBackend
+
+	mu      sync.RWMutex
+	current *types.Header
+
+	scope event.SubscriptionScope
+	// chainFeed/chainHeadFeed fire whenever AdvanceHead accepts a new head.
+	// sideFeed/rmLogsFeed/logsFeed exist only so LightChain's subscription
+	// surface mirrors core.BlockChain's; a light client never reorgs a local
+	// side chain or processes logs itself, so nothing is ever posted to them.
+	chainFeed     event.Feed
+	chainHeadFeed event.Feed
+	sideFeed      event.Feed
+	rmLogsFeed    event.Feed
+	logsFeed      event.Feed
+}
+
+// NewLightChain creates a LightChain that resolves headers/bodies/receipts it
+// does not already know about through odr.
+func NewLightChain(odr OdrBackend, chainConfig *params.ChainConfig) (*LightChain, error) {
+	bc := &LightChain{odr: odr, chainConfig: chainConfig}
+	genesis := core.GetHeader(odr.Database(), core.GetCanonicalHash(odr.Database(), 0), 0)
+	bc.current = genesis
+	return bc, nil
+}
+
+// CurrentHeader returns the most recent header announced by a peer.
+func (lc *LightChain) CurrentHeader() *types.Header {
+	lc.mu.RLock()
+	defer lc.mu.RUnlock()
+	return lc.current
+}
+
+// Odr returns the ODR backend headers/state are resolved through, e.g. so a
+// caller holding a LightChain can derive an on-demand light.State from it.
+func (lc *LightChain) Odr() OdrBackend {
+	return lc.odr
+}
+
+// AdvanceHead accepts header as the new chain head once a les peer has
+// announced it, persisting it locally so future lookups (and ChtIndexer
+// section heads) can see it without going back out to the network.
+func (lc *LightChain) AdvanceHead(header *types.Header) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	if lc.current != nil && header.Number.Uint64() <= lc.current.Number.Uint64() {
+		return
+	}
+	core.WriteHeader(lc.odr.Database(), header)
+	core.WriteCanonicalHash(lc.odr.Database(), header.Hash(), header.Number.Uint64())
+	lc.current = header
+
+	block := types.NewBlockWithHeader(header)
+	lc.chainFeed.Send(core.ChainEvent{Block: block, Hash: header.Hash()})
+	lc.chainHeadFeed.Send(core.ChainHeadEvent{Block: block})
+}
+
+// SubscribeChainEvent registers a subscription for new canonical heads
+// accepted via AdvanceHead.
+func (lc *LightChain) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription {
+	return lc.scope.Track(lc.chainFeed.Subscribe(ch))
+}
+
+// SubscribeChainHeadEvent registers a subscription for new canonical heads
+// accepted via AdvanceHead.
+func (lc *LightChain) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
+	return lc.scope.Track(lc.chainHeadFeed.Subscribe(ch))
+}
+
+// SubscribeChainSideEvent registers a subscription for side-chain blocks. A
+// light client never maintains a local side chain, so nothing is ever
+// published on it; it exists purely so LightChain satisfies the same
+// subscription surface as core.BlockChain.
+func (lc *LightChain) SubscribeChainSideEvent(ch chan<- core.ChainSideEvent) event.Subscription {
+	return lc.scope.Track(lc.sideFeed.Subscribe(ch))
+}
+
+// SubscribeRemovedLogsEvent registers a subscription for logs removed by a
+// reorg. A light client does not process logs locally, so nothing is ever
+// published on it.
+func (lc *LightChain) SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription {
+	return lc.scope.Track(lc.rmLogsFeed.Subscribe(ch))
+}
+
+// SubscribeLogsEvent registers a subscription for logs included in newly
+// imported blocks. A light client does not process logs locally, so nothing
+// is ever published on it.
+func (lc *LightChain) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription {
+	return lc.scope.Track(lc.logsFeed.Subscribe(ch))
+}
+
+// SetHead rewinds the local head back to number, e.g. after a canonical chain
+// reorg is detected via CHT validation.
+func (lc *LightChain) SetHead(number uint64) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	lc.current = core.GetHeader(lc.odr.Database(), core.GetCanonicalHash(lc.odr.Database(), number), number)
+}
+
+// GetTdByHash returns the total difficulty of the header identified by hash
+// as stored in the local database, or nil if none is stored. AdvanceHead
+// does not store a total difficulty.
+func (lc *LightChain) GetTdByHash(hash common.Hash) *big.Int {
+	number := core.GetBlockNumber(lc.odr.Database(), hash)
+	return core.GetTd(lc.odr.Database(), hash, number)
+}
+
+// GetHeaderByNumberOdr returns the canonical header at number, retrieving it
+// from a peer via an inclusion proof against the CHT if it is older than the
+// locally known canonical chain.
+func (lc *LightChain) GetHeaderByNumberOdr(ctx context.Context, number uint64) (*types.Header, error) {
+	if header := core.GetHeader(lc.odr.Database(), core.GetCanonicalHash(lc.odr.Database(), number), number); header != nil {
+		return header, nil
+	}
+	req := &ChtRequest{ChtRoot: lc.odr.ChtIndexer().SectionHead(number), Number: number}
+	if err := lc.odr.Retrieve(ctx, req); err != nil {
+		return nil, err
+	}
+	// StoreResult only stores the header if its CHT proof checks out.
+	req.StoreResult(lc.odr.Database())
+	header := core.GetHeader(lc.odr.Database(), core.GetCanonicalHash(lc.odr.Database(), number), number)
+	if header == nil {
+		return nil, errUnprovenHeader
+	}
+	return header, nil
+}
+
+// GetBlockByHashOdr returns the block identified by hash, fetching its body
+// from a peer if necessary and pairing it with the locally held header.
+func (lc *LightChain) GetBlockByHashOdr(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	number := core.GetBlockNumber(lc.odr.Database(), hash)
+	header := core.GetHeader(lc.odr.Database(), hash, number)
+	if header == nil {
+		return nil, core.ErrUnknownAncestor
+	}
+	req := &BlockRequest{Hash: hash, Number: number}
+	if err := lc.odr.Retrieve(ctx, req); err != nil {
+		return nil, err
+	}
+	body := new(types.Body)
+	if err := rlp.DecodeBytes(req.Rlp, body); err != nil {
+		return nil, err
+	}
+	block := types.NewBlockWithHeader(header).WithBody(body.Transactions, body.Uncles)
+	if types.DeriveSha(block.Transactions()) != header.TxHash || types.CalcUncleHash(block.Uncles()) != header.UncleHash {
+		return nil, errInvalidBody
+	}
+	return block, nil
+}
+
+// ChtRequest is an OdrRequest asking a peer for a header together with its
+// Merkle proof against the canonical hash trie (CHT) section root ChtRoot.
+type ChtRequest struct {
+	ChtRoot common.Hash
+	Number  uint64
+	Header  *types.Header
+	Proof   []ProofNode
+}
+
+// chtLeaf is the value a canonical hash trie leaf resolves to: the canonical
+// header's own hash and total difficulty at that number.
+type chtLeaf struct {
+	Hash common.Hash
+	Td   *big.Int
+}
+
+// chtKey encodes number the way the canonical hash trie indexes its leaves,
+// as an 8-byte big-endian block number.
+func chtKey(number uint64) []byte {
+	var enc [8]byte
+	binary.BigEndian.PutUint64(enc[:], number)
+	return enc[:]
+}
+
+// StoreResult verifies that Proof resolves, against ChtRoot, to a CHT leaf
+// attesting to Header's own hash before trusting it as canonical - without
+// that check a single malicious peer could rewrite history this client has
+// never seen for itself. A header whose proof doesn't check out (including a
+// peer that simply didn't send one) is dropped rather than stored.
+func (req *ChtRequest) StoreResult(db ethdb.Database) {
+	if req.Header == nil {
+		return
+	}
+	proofDb := ethdb.NewMemDatabase()
+	for _, node := range req.Proof {
+		if crypto.Keccak256Hash(node.Val) != node.Hash {
+			return
+		}
+		proofDb.Put(node.Hash[:], node.Val)
+	}
+	value, err := trie.VerifyProof(req.ChtRoot, chtKey(req.Number), proofDb)
+	if err != nil || value == nil {
+		return
+	}
+	var leaf chtLeaf
+	if err := rlp.DecodeBytes(value, &leaf); err != nil || leaf.Hash != req.Header.Hash() {
+		return
+	}
+	core.WriteHeader(db, req.Header)
+	core.WriteCanonicalHash(db, req.Header.Hash(), req.Header.Number.Uint64())
+}
@@ -0,0 +1,163 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package light implements on-demand retrieval capable state and chain
+// objects for the Expanse Light Client.
+package light
+
+import (
+	"context"
+	"errors"
+
+	"github.com/expanse-org/go-expanse/common"
+	"github.com/expanse-org/go-expanse/core"
+	"github.com/expanse-org/go-expanse/core/types"
+	"github.com/expanse-org/go-expanse/crypto"
+	"github.com/expanse-org/go-expanse/ethdb"
+	"github.com/expanse-org/go-expanse/trie"
+)
+
+// NoOdr is the default context passed to an ODR capable function when the
+// caller does not request on-demand retrieval.
+var NoOdr = context.Background()
+
+// ErrNoPeers is returned by Retrieve when no suitable peer is available to
+// serve the request. Retrieve returns it at once; it does not wait for a new
+// peer to connect.
+var ErrNoPeers = errors.New("no suitable peers available")
+
+// OdrBackend is an interface to a backend service that allows retrieving chain
+// data from peers on-demand. It is implemented by les.LesOdr for the light
+// client and used by the data structures in this package to resolve data that
+// is not already available locally.
+type OdrBackend interface {
+	Database() ethdb.Database
+	ChtIndexer() ChainIndexer
+	BloomTrieIndexer() ChainIndexer
+	BloomIndexer() ChainIndexer
+	Retrieve(ctx context.Context, req OdrRequest) error
+}
+
+// ChainIndexer is the minimal interface OdrBackend needs from the indexers
+// that track auxiliary chain/bloom tries used to answer ODR requests without
+// contacting a peer (e.g. canonical hash tries).
+type ChainIndexer interface {
+	SectionHead(index uint64) common.Hash
+}
+
+// OdrRequest is an implementation of a data retrieval function that is
+// dispatched to remote peers by the LES protocol manager. StoreResult is
+// called once the request has been successfully answered so the retrieved
+// data can be cached locally.
+type OdrRequest interface {
+	StoreResult(db ethdb.Database)
+}
+
+// TrieID identifies a state or storage trie by the block it belongs to plus
+// its root hash, so that a TrieRequest can be routed to a peer that has the
+// corresponding block available.
+type TrieID struct {
+	BlockHash   common.Hash
+	BlockNumber uint64
+	Root        common.Hash
+	AccKey      []byte
+}
+
+// StateTrieID returns the ID of the account trie belonging to header.
+func StateTrieID(header *types.Header) *TrieID {
+	return &TrieID{
+		BlockHash:   header.Hash(),
+		BlockNumber: header.Number.Uint64(),
+		Root:        header.Root,
+	}
+}
+
+// StorageTrieID returns the ID of a contract storage trie belonging to
+// account accKey at state root state.
+func StorageTrieID(state *TrieID, accKey []byte, root common.Hash) *TrieID {
+	return &TrieID{
+		BlockHash:   state.BlockHash,
+		BlockNumber: state.BlockNumber,
+		Root:        root,
+		AccKey:      accKey,
+	}
+}
+
+// TrieRequest is an OdrRequest asking a peer for the trie nodes along the
+// path to key inside the trie identified by Id.
+type TrieRequest struct {
+	Id    *TrieID
+	Key   []byte
+	Proof []ProofNode
+}
+
+// StoreResult verifies that every proof node is correctly content-addressed
+// and that the proof actually resolves, against Id.Root, to Key, before
+// caching any of it. Proof comes straight from a remote peer; db is the
+// single content-addressed store every trie (of every account) reads
+// through, so a single unverified node would let one malicious answer poison
+// lookups far beyond the path it was meant to cover. A proof that fails
+// either check is simply dropped rather than cached - the caller sees the
+// same missing node it started with and retries.
+func (req *TrieRequest) StoreResult(db ethdb.Database) {
+	proofDb := ethdb.NewMemDatabase()
+	for _, node := range req.Proof {
+		if crypto.Keccak256Hash(node.Val) != node.Hash {
+			return
+		}
+		proofDb.Put(node.Hash[:], node.Val)
+	}
+	if _, err := trie.VerifyProof(req.Id.Root, req.Key, proofDb); err != nil {
+		return
+	}
+	for _, node := range req.Proof {
+		db.Put(node.Hash[:], node.Val)
+	}
+}
+
+// ProofNode is a single proof element returned for a TrieRequest, exported so
+// the les package can populate it directly from a decoded GetProofsV2Msg reply.
+type ProofNode struct {
+	Hash common.Hash
+	Val  []byte
+}
+
+// BlockRequest is an OdrRequest asking a peer for the body of the block with
+// the given hash/number.
+type BlockRequest struct {
+	Hash   common.Hash
+	Number uint64
+	Rlp    []byte
+}
+
+// StoreResult stores the raw RLP-encoded body under its canonical database key.
+func (req *BlockRequest) StoreResult(db ethdb.Database) {
+	db.Put(append([]byte("body-"), req.Hash[:]...), req.Rlp)
+}
+
+// ReceiptsRequest is an OdrRequest asking a peer for the receipts of the
+// block with the given hash/number.
+type ReceiptsRequest struct {
+	Hash     common.Hash
+	Number   uint64
+	Receipts types.Receipts
+}
+
+// StoreResult stores the retrieved receipts so GetBlockReceipts does not need
+// to go out to the network again for the same block.
+func (req *ReceiptsRequest) StoreResult(db ethdb.Database) {
+	core.WriteBlockReceipts(db, req.Hash, req.Number, req.Receipts)
+}
@@ -0,0 +1,130 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package light
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/expanse-org/go-expanse/common"
+	"github.com/expanse-org/go-expanse/common/hexutil"
+	"github.com/expanse-org/go-expanse/core/state"
+	"github.com/expanse-org/go-expanse/core/types"
+	"github.com/expanse-org/go-expanse/ethdb"
+	"github.com/expanse-org/go-expanse/internal/ethapi"
+)
+
+// State implements ethapi.State on top of a *state.StateDB whose underlying
+// trie reads are served on demand through ODR, so a light client can answer
+// eth_call/eth_getBalance/... without holding the full state locally.
+type State struct {
+	state *state.StateDB
+}
+
+// NewState returns the State of header, lazily resolving trie nodes for it
+// through odr as they are accessed. The returned value satisfies ethapi.State.
+func NewState(ctx context.Context, header *types.Header, odr OdrBackend) (*State, error) {
+	id := StateTrieID(header)
+	stateDb, err := state.New(header.Root, NewStateDatabase(ctx, id, odr))
+	if err != nil {
+		return nil, err
+	}
+	return &State{state: stateDb}, nil
+}
+
+// StateDB exposes the underlying state.StateDB, e.g. for GetEVM to fund the
+// caller of an eth_call with the maximum possible balance.
+func (s *State) StateDB() *state.StateDB {
+	return s.state
+}
+
+// Copy returns an independent snapshot of the state, so callers that need to
+// try several speculative executions (e.g. eth_createAccessList's
+// convergence loop) can discard one attempt's mutations without disturbing
+// the original or re-resolving trie nodes already fetched through ODR.
+func (s *State) Copy() ethapi.State {
+	return &State{state: s.state.Copy()}
+}
+
+// GetBalance implements ethapi.State.
+func (s *State) GetBalance(ctx context.Context, addr common.Address) (*big.Int, error) {
+	return s.state.GetBalance(addr), s.state.Error()
+}
+
+// GetCode implements ethapi.State.
+func (s *State) GetCode(ctx context.Context, addr common.Address) ([]byte, error) {
+	return s.state.GetCode(addr), s.state.Error()
+}
+
+// GetState implements ethapi.State.
+func (s *State) GetState(ctx context.Context, addr common.Address, key common.Hash) (common.Hash, error) {
+	return s.state.GetState(addr, key), s.state.Error()
+}
+
+// GetNonce implements ethapi.State.
+func (s *State) GetNonce(ctx context.Context, addr common.Address) (uint64, error) {
+	return s.state.GetNonce(addr), s.state.Error()
+}
+
+// GetProof returns the EIP-1186 account and storage proofs for addr at this
+// state, resolving whatever trie nodes the proof touches through ODR the
+// same way every other State accessor does. It satisfies the same
+// unexported interface internal/ethapi.GetProof type-asserts for, mirroring
+// eth.EthApiState.GetProof.
+func (s *State) GetProof(ctx context.Context, addr common.Address, storageKeys []string) (*ethapi.AccountResult, error) {
+	accountProofDb, err := s.state.GetProof(addr)
+	if err != nil {
+		return nil, err
+	}
+	storageHash := common.Hash{}
+	if trie := s.state.StorageTrie(addr); trie != nil {
+		storageHash = trie.Hash()
+	}
+	result := &ethapi.AccountResult{
+		Address:      addr,
+		AccountProof: nodeListFromDb(accountProofDb),
+		Balance:      (*hexutil.Big)(s.state.GetBalance(addr)),
+		CodeHash:     s.state.GetCodeHash(addr),
+		Nonce:        hexutil.Uint64(s.state.GetNonce(addr)),
+		StorageHash:  storageHash,
+	}
+	for _, key := range storageKeys {
+		hash := common.HexToHash(key)
+		storageProofDb, err := s.state.GetStorageProof(addr, hash)
+		if err != nil {
+			return nil, err
+		}
+		result.StorageProof = append(result.StorageProof, ethapi.StorageResult{
+			Key:   key,
+			Value: (*hexutil.Big)(s.state.GetState(addr, hash).Big()),
+			Proof: nodeListFromDb(storageProofDb),
+		})
+	}
+	return result, nil
+}
+
+// nodeListFromDb drains a proof database produced by state.StateDB.GetProof
+// into the hex-encoded node list expected by the eth_getProof response.
+func nodeListFromDb(db *ethdb.MemDatabase) []string {
+	var nodes []string
+	for _, key := range db.Keys() {
+		if val, err := db.Get(key); err == nil {
+			nodes = append(nodes, hexutil.Encode(val))
+		}
+	}
+	return nodes
+}
@@ -0,0 +1,137 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package light
+
+import (
+	"context"
+
+	"github.com/expanse-org/go-expanse/common"
+	"github.com/expanse-org/go-expanse/core/state"
+	"github.com/expanse-org/go-expanse/trie"
+)
+
+// odrDatabase is a state.Database that serves trie nodes missing from the
+// local key/value store by issuing TrieRequests against an OdrBackend. It is
+// what NewState hands to state.New so that ordinary StateDB accessors work
+// unmodified on a light client.
+type odrDatabase struct {
+	ctx context.Context
+	id  *TrieID
+	odr OdrBackend
+	state.Database
+}
+
+// NewStateDatabase returns a state.Database for the state trie identified by
+// id, resolving any trie node missing from odr.Database() through odr.
+func NewStateDatabase(ctx context.Context, id *TrieID, odr OdrBackend) state.Database {
+	return &odrDatabase{ctx: ctx, id: id, odr: odr, Database: state.NewDatabase(odr.Database())}
+}
+
+// OpenTrie implements state.Database.
+func (db *odrDatabase) OpenTrie(root common.Hash) (state.Trie, error) {
+	return &odrTrie{db: db, id: db.id}, nil
+}
+
+// OpenStorageTrie implements state.Database.
+func (db *odrDatabase) OpenStorageTrie(addrHash, root common.Hash) (state.Trie, error) {
+	return &odrTrie{db: db, id: StorageTrieID(db.id, addrHash[:], root)}, nil
+}
+
+// odrTrie wraps trie.Trie and resolves missing nodes through ODR the first
+// time a given path is touched, instead of returning trie.MissingNodeError.
+type odrTrie struct {
+	db *odrDatabase
+	id *TrieID
+	tr *trie.Trie
+}
+
+// do resolves t's backing trie, fetching it node by node on first use.
+func (t *odrTrie) do(key []byte, fn func() error) error {
+	for {
+		err := fn()
+		if _, ok := err.(*trie.MissingNodeError); !ok {
+			return err
+		}
+		req := &TrieRequest{Id: t.id, Key: key}
+		if err := t.db.odr.Retrieve(t.db.ctx, req); err != nil {
+			return err
+		}
+		req.StoreResult(t.db.odr.Database())
+		t.tr = nil // force re-open against the now-populated database
+	}
+}
+
+func (t *odrTrie) open() (*trie.Trie, error) {
+	if t.tr == nil {
+		tr, err := trie.New(t.id.Root, trie.NewDatabase(t.db.odr.Database()))
+		if err != nil {
+			return nil, err
+		}
+		t.tr = tr
+	}
+	return t.tr, nil
+}
+
+// TryGet implements state.Trie, transparently retrying through ODR.
+func (t *odrTrie) TryGet(key []byte) ([]byte, error) {
+	var result []byte
+	err := t.do(key, func() error {
+		tr, err := t.open()
+		if err != nil {
+			return err
+		}
+		result, err = tr.TryGet(key)
+		return err
+	})
+	return result, err
+}
+
+// TryUpdate implements state.Trie.
+func (t *odrTrie) TryUpdate(key, value []byte) error {
+	return t.do(key, func() error {
+		tr, err := t.open()
+		if err != nil {
+			return err
+		}
+		return tr.TryUpdate(key, value)
+	})
+}
+
+// TryDelete implements state.Trie.
+func (t *odrTrie) TryDelete(key []byte) error {
+	return t.do(key, func() error {
+		tr, err := t.open()
+		if err != nil {
+			return err
+		}
+		return tr.TryDelete(key)
+	})
+}
+
+// Commit implements state.Trie.
+func (t *odrTrie) Commit(onleaf trie.LeafCallback) (common.Hash, error) {
+	tr, err := t.open()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return tr.Commit(onleaf)
+}
+
+// Hash implements state.Trie.
+func (t *odrTrie) Hash() common.Hash {
+	return t.id.Root
+}
@@ -0,0 +1,136 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package light
+
+import (
+	"context"
+	"sync"
+
+	"github.com/expanse-org/go-expanse/common"
+	"github.com/expanse-org/go-expanse/core"
+	"github.com/expanse-org/go-expanse/core/types"
+	"github.com/expanse-org/go-expanse/event"
+	"github.com/expanse-org/go-expanse/params"
+)
+
+// TxPool is a light-client stand-in for core.TxPool. It cannot validate
+// transactions against local state (it has none), so it only tracks locally
+// submitted transactions long enough to answer eth_getTransactionByHash and
+// hands them off to relay for actual propagation/inclusion by a full peer.
+type TxPool struct {
+	config *params.ChainConfig
+	chain  *LightChain
+	relay  func(tx *types.Transaction) error
+
+	scope  event.SubscriptionScope
+	txFeed event.Feed
+
+	mu  sync.RWMutex
+	all map[common.Hash]*types.Transaction
+}
+
+// NewTxPool creates a light transaction pool that forwards added transactions
+// to relay instead of including them in locally mined blocks.
+func NewTxPool(config *params.ChainConfig, chain *LightChain, relay func(tx *types.Transaction) error) *TxPool {
+	return &TxPool{
+		config: config,
+		chain:  chain,
+		relay:  relay,
+		all:    make(map[common.Hash]*types.Transaction),
+	}
+}
+
+// Add validates tx's signature, relays it to a full-node peer and remembers
+// it locally so GetTransaction can find it again.
+func (pool *TxPool) Add(ctx context.Context, tx *types.Transaction) error {
+	if _, err := types.Sender(types.NewEIP155Signer(pool.config.ChainId), tx); err != nil {
+		return err
+	}
+	if err := pool.relay(tx); err != nil {
+		return err
+	}
+	pool.mu.Lock()
+	pool.all[tx.Hash()] = tx
+	pool.mu.Unlock()
+	pool.txFeed.Send(core.TxPreEvent{Tx: tx})
+	return nil
+}
+
+// SubscribeTxPreEvent registers a subscription for transactions this pool
+// relays, fired once per successful Add.
+func (pool *TxPool) SubscribeTxPreEvent(ch chan<- core.TxPreEvent) event.Subscription {
+	return pool.scope.Track(pool.txFeed.Subscribe(ch))
+}
+
+// RemoveTx forgets a previously added transaction, e.g. once it has been seen
+// included in a block.
+func (pool *TxPool) RemoveTx(hash common.Hash) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	delete(pool.all, hash)
+}
+
+// GetTransaction returns a previously added transaction by hash, or nil.
+func (pool *TxPool) GetTransaction(hash common.Hash) *types.Transaction {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+	return pool.all[hash]
+}
+
+// GetTransactions returns all locally submitted, not-yet-removed transactions.
+func (pool *TxPool) GetTransactions() types.Transactions {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	txs := make(types.Transactions, 0, len(pool.all))
+	for _, tx := range pool.all {
+		txs = append(txs, tx)
+	}
+	return txs
+}
+
+// GetNonce returns the next nonce addr should use, resolved from the light
+// state of the current head since the pool does not track pending nonces.
+// A nonce for a transaction this pool itself just relayed but that has not
+// yet been mined is not reflected until the peer includes it.
+func (pool *TxPool) GetNonce(ctx context.Context, addr common.Address) (uint64, error) {
+	head := pool.chain.CurrentHeader()
+	state, err := NewState(ctx, head, pool.chain.Odr())
+	if err != nil {
+		return 0, err
+	}
+	return state.GetNonce(ctx, addr)
+}
+
+// Stats returns the number of locally tracked transactions.
+func (pool *TxPool) Stats() int {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+	return len(pool.all)
+}
+
+// Content returns the locally tracked transactions, all reported as pending
+// since the light client has no notion of a queue.
+func (pool *TxPool) Content() (map[common.Address]types.Transactions, map[common.Address]types.Transactions) {
+	pending := make(map[common.Address]types.Transactions)
+	for _, tx := range pool.GetTransactions() {
+		if signer, err := types.Sender(types.NewEIP155Signer(pool.config.ChainId), tx); err == nil {
+			pending[signer] = append(pending[signer], tx)
+		}
+	}
+	return pending, make(map[common.Address]types.Transactions)
+}
@@ -0,0 +1,147 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package trie
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/expanse-org/go-expanse/common"
+	"github.com/expanse-org/go-expanse/crypto"
+	"github.com/expanse-org/go-expanse/ethdb"
+	"github.com/expanse-org/go-expanse/rlp"
+)
+
+// Prove constructs a Merkle proof for key: every encoded node on the path
+// from the root to the leaf is written into proofDb, keyed by its keccak256
+// hash, so a caller holding only the root hash can later verify that key maps
+// (or does not map) to the value currently stored at it. If fromLevel is
+// non-zero, that many levels closest to the root are skipped, letting a
+// caller who already proved a shallower path send only the remainder.
+func (t *Trie) Prove(key []byte, fromLevel uint, proofDb ethdb.KeyValueWriter) error {
+	key = keybytesToHex(key)
+
+	var nodes []node
+	tn := t.root
+	for len(key) > 0 && tn != nil {
+		switch n := tn.(type) {
+		case *shortNode:
+			if len(key) < len(n.Key) || !bytes.Equal(n.Key, key[:len(n.Key)]) {
+				tn = nil // trie doesn't contain the key
+			} else {
+				tn = n.Val
+				key = key[len(n.Key):]
+			}
+			nodes = append(nodes, n)
+		case *fullNode:
+			tn = n.Children[key[0]]
+			key = key[1:]
+			nodes = append(nodes, n)
+		case hashNode:
+			resolved, err := t.resolveHash(n, nil)
+			if err != nil {
+				return err
+			}
+			tn = resolved
+		default:
+			tn = nil
+		}
+	}
+	for _, n := range nodes {
+		if fromLevel > 0 {
+			fromLevel--
+			continue
+		}
+		enc, err := rlp.EncodeToBytes(n)
+		if err != nil {
+			return err
+		}
+		proofDb.Put(crypto.Keccak256(enc), enc)
+	}
+	return nil
+}
+
+// VerifyProof checks that proofDb contains every node needed to walk rootHash
+// down to key, as produced by Trie.Prove/SecureTrie.Prove, and returns the
+// value stored at key, or a nil value if the proof establishes that key is
+// absent. It is the only way untrusted proof data received from a peer
+// (e.g. TrieRequest/ChtRequest.Proof) should ever be trusted: a proof that
+// doesn't verify must not be cached.
+func VerifyProof(rootHash common.Hash, key []byte, proofDb ethdb.KeyValueReader) (value []byte, err error) {
+	key = keybytesToHex(key)
+	wantHash := rootHash
+	for i := 0; ; i++ {
+		buf, _ := proofDb.Get(wantHash[:])
+		if buf == nil {
+			return nil, fmt.Errorf("proof node %d (hash %x) missing", i, wantHash)
+		}
+		n, err := decodeNode(wantHash[:], buf)
+		if err != nil {
+			return nil, fmt.Errorf("bad proof node %d: %v", i, err)
+		}
+		keyrest, cld := get(n, key)
+		switch cld := cld.(type) {
+		case nil:
+			// The proof proves that key does not exist in the trie.
+			return nil, nil
+		case hashNode:
+			key = keyrest
+			wantHash = common.BytesToHash(cld)
+		case valueNode:
+			return cld, nil
+		}
+	}
+}
+
+// get traverses the single decoded node tn along key, returning whichever
+// child the next proof step needs: a hashNode to resolve from proofDb next,
+// a valueNode holding the leaf value itself, or nil if tn proves key cannot
+// be present.
+func get(tn node, key []byte) ([]byte, node) {
+	for {
+		switch n := tn.(type) {
+		case *shortNode:
+			if len(key) < len(n.Key) || !bytes.Equal(n.Key, key[:len(n.Key)]) {
+				return nil, nil
+			}
+			tn = n.Val
+			key = key[len(n.Key):]
+		case *fullNode:
+			if len(key) == 0 {
+				return nil, nil
+			}
+			tn = n.Children[key[0]]
+			key = key[1:]
+		case hashNode:
+			return key, n
+		case valueNode:
+			return nil, n
+		case nil:
+			return nil, nil
+		default:
+			return nil, nil
+		}
+	}
+}
+
+// Prove constructs a Merkle proof for key exactly like Trie.Prove, first
+// hashing key the same way every other SecureTrie accessor does (t.hashKey),
+// since the account/storage tries this type backs are keyed by
+// keccak256(key), not key itself.
+func (t *SecureTrie) Prove(key []byte, fromLevel uint, proofDb ethdb.KeyValueWriter) error {
+	return t.trie.Prove(t.hashKey(key), fromLevel, proofDb)
+}